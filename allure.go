@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/joaopsramos/gotestpp/utils"
+)
+
+// AllureReporter writes an Allure results directory, see https://allurereport.org/docs/how-it-works-test-result-file/
+type AllureReporter struct {
+	dir string
+}
+
+type allureResult struct {
+	UUID          string             `json:"uuid"`
+	HistoryID     string             `json:"historyId"`
+	Name          string             `json:"name"`
+	FullName      string             `json:"fullName"`
+	Status        string             `json:"status"`
+	StatusDetails allureStatus       `json:"statusDetails"`
+	Stage         string             `json:"stage"`
+	Start         int64              `json:"start"`
+	Stop          int64              `json:"stop"`
+	Labels        []allureLabel      `json:"labels"`
+	Attachments   []allureAttachment `json:"attachments"`
+}
+
+type allureStatus struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+	Flaky   bool   `json:"flaky,omitempty"`
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type allureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+func NewAllureReporter(dir string) *AllureReporter {
+	return &AllureReporter{dir: dir}
+}
+
+func (a *AllureReporter) Report(run *Run) error {
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, pkg := range run.Packages {
+		for _, t := range pkg.AllTests() {
+			if err := a.writeTest(t); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *AllureReporter) writeTest(t TestEntry) error {
+	uuid, err := newUUID()
+	if err != nil {
+		return err
+	}
+
+	fullName := t.Pkg + "/" + t.Name
+	historyID := md5.Sum([]byte(fullName))
+
+	result := allureResult{
+		UUID:          uuid,
+		HistoryID:     hex.EncodeToString(historyID[:]),
+		Name:          path.Base(t.Name),
+		FullName:      fullName,
+		Status:        allureStatusOf(t),
+		StatusDetails: allureStatusDetails(t),
+		Stage:         "finished",
+		Start:         unixMilli(t.Start),
+		Stop:          unixMilli(t.End),
+		Labels:        allureLabels(t),
+		Attachments:   []allureAttachment{},
+	}
+
+	if strings.TrimSpace(t.Output) != "" {
+		source := uuid + "-attachment.txt"
+		if err := os.WriteFile(filepath.Join(a.dir, source), []byte(t.Output), 0o644); err != nil {
+			return err
+		}
+
+		result.Attachments = append(result.Attachments, allureAttachment{Name: "Output", Source: source, Type: "text/plain"})
+	}
+
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(a.dir, uuid+"-result.json"), output, 0o644)
+}
+
+func allureStatusOf(t TestEntry) string {
+	switch t.Status() {
+	case "pass":
+		return "passed"
+	case "skip":
+		return "skipped"
+	case "fail":
+		// Allure uses broken for unexpected errors, failed is kept for assertions
+		if t.Panicked || t.Unfinished {
+			return "broken"
+		}
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+func allureStatusDetails(t TestEntry) allureStatus {
+	switch t.Action {
+	case "skip":
+		return allureStatus{Message: t.SkipReason()}
+
+	case "fail":
+		// Flaky tests are passed, but their first failure is kept
+		if asserts := ParseTestifyAsserts(t.Output); len(asserts) > 0 {
+			return allureStatus{Message: assertMessage(asserts[0]), Trace: strings.Join(asserts[0].TraceLines(), "\n"), Flaky: t.Flaky}
+		}
+
+		status := allureStatus{Trace: utils.StripANSI(formatOutput(t)), Flaky: t.Flaky}
+		if locations := TestLocations(t); len(locations) > 0 {
+			status.Message = locations[0].Message
+		}
+
+		return status
+	}
+
+	return allureStatus{}
+}
+
+// allureLabels groups the tests by package, root test and the parents of nested subtests.
+func allureLabels(t TestEntry) []allureLabel {
+	labels := []allureLabel{
+		{Name: "package", Value: t.Pkg},
+		{Name: "parentSuite", Value: t.Pkg},
+		{Name: "framework", Value: "go test"},
+		{Name: "language", Value: "go"},
+	}
+
+	parents := strings.Split(t.Name, "/")
+	parents = parents[:len(parents)-1]
+
+	if len(parents) > 0 {
+		labels = append(labels, allureLabel{Name: "suite", Value: parents[0]})
+	}
+
+	if len(parents) > 1 {
+		labels = append(labels, allureLabel{Name: "subSuite", Value: strings.Join(parents[1:], "/")})
+	}
+
+	return labels
+}
+
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	// Version 4, variant 10
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_allureReport(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	dir := filepath.Join(t.TempDir(), "allure-results")
+	processTestdata(t, NewProcessor(Config{AllureDir: dir}), "testify_fail.txt")
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-result.json"))
+	r.NoError(err)
+	a.Len(files, 100)
+
+	results := map[string]allureResult{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		r.NoError(err)
+
+		var result allureResult
+		r.NoError(json.Unmarshal(content, &result))
+		results[result.FullName] = result
+	}
+
+	pkg := "github.com/joaopsramos/fincon/internal/service"
+	result, ok := results[pkg+"/TestPostgresExpense_GetSummary/should_handle_next_month_with_carried_over_excesses"]
+	r.True(ok)
+
+	a.Equal("should_handle_next_month_with_carried_over_excesses", result.Name)
+	a.Equal("failed", result.Status)
+	a.Equal("/home/joao/www/fincon/backend/internal/service/expense_test.go:97\n"+
+		"/home/joao/www/fincon/backend/internal/service/expense_test.go:199", result.StatusDetails.Trace)
+	a.Contains(result.StatusDetails.Message, "Not equal:\nexpected: ")
+	a.NotZero(result.Start)
+	a.Contains(result.Labels, allureLabel{Name: "parentSuite", Value: pkg})
+	a.Contains(result.Labels, allureLabel{Name: "suite", Value: "TestPostgresExpense_GetSummary"})
+
+	r.Len(result.Attachments, 1)
+	attachment, err := os.ReadFile(filepath.Join(dir, result.Attachments[0].Source))
+	r.NoError(err)
+	a.Contains(string(attachment), "Error Trace:")
+}
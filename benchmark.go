@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// The name ends with the GOMAXPROCS suffix, which go test omits when it's 1
+var benchmarkRe = regexp.MustCompile(`^(Benchmark\S*?)(?:-(\d+))?\s+(\d+)\s+(\S.*)$`)
+
+type Benchmark struct {
+	Pkg         string            `json:"pkg"`
+	Name        string            `json:"name"`
+	Procs       int               `json:"procs"`
+	Iterations  int               `json:"iterations"`
+	NsPerOp     float64           `json:"nsPerOp"`
+	BytesPerOp  *int64            `json:"bytesPerOp,omitempty"`
+	AllocsPerOp *int64            `json:"allocsPerOp,omitempty"`
+	Metrics     []BenchmarkMetric `json:"metrics,omitempty"`
+}
+
+// BenchmarkMetric is any other value of a benchmark, e.g. MB/s or the ones reported with b.ReportMetric.
+type BenchmarkMetric struct {
+	Unit  string  `json:"unit"`
+	Value float64 `json:"value"`
+}
+
+// ParseBenchmark parses a benchmark result line, e.g. "BenchmarkJoin-8   2000   79.87 ns/op   24 B/op".
+func ParseBenchmark(line string) (Benchmark, bool) {
+	matches := benchmarkRe.FindStringSubmatch(strings.TrimSpace(line))
+	if matches == nil {
+		return Benchmark{}, false
+	}
+
+	b := Benchmark{Name: matches[1], Procs: 1}
+	b.Iterations, _ = strconv.Atoi(matches[3])
+
+	if matches[2] != "" {
+		b.Procs, _ = strconv.Atoi(matches[2])
+	}
+
+	fields := strings.Fields(matches[4])
+	if len(fields)%2 != 0 {
+		return Benchmark{}, false
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Benchmark{}, false
+		}
+
+		switch unit := fields[i+1]; unit {
+		case "ns/op":
+			b.NsPerOp = value
+		case "B/op":
+			bytes := int64(value)
+			b.BytesPerOp = &bytes
+		case "allocs/op":
+			allocs := int64(value)
+			b.AllocsPerOp = &allocs
+		default:
+			b.Metrics = append(b.Metrics, BenchmarkMetric{Unit: unit, Value: value})
+		}
+	}
+
+	return b, true
+}
+
+// FullName returns the name as printed by go test, with the GOMAXPROCS suffix.
+func (b Benchmark) FullName() string {
+	if b.Procs > 1 {
+		return fmt.Sprintf("%s-%d", b.Name, b.Procs)
+	}
+
+	return b.Name
+}
+
+// Measurements returns the value of each unit reported by the benchmark.
+func (b Benchmark) Measurements() map[string]float64 {
+	values := map[string]float64{"ns/op": b.NsPerOp}
+
+	if b.BytesPerOp != nil {
+		values["B/op"] = float64(*b.BytesPerOp)
+	}
+
+	if b.AllocsPerOp != nil {
+		values["allocs/op"] = float64(*b.AllocsPerOp)
+	}
+
+	for _, m := range b.Metrics {
+		values[m.Unit] = m.Value
+	}
+
+	return values
+}
+
+// benchmarkUnits returns the units reported by the benchmarks, the standard ones first and then the others
+// in the order they appear.
+func benchmarkUnits(benchmarks []Benchmark) []string {
+	units := []string{"ns/op", "B/op", "allocs/op"}
+	for _, b := range benchmarks {
+		for _, m := range b.Metrics {
+			if !slices.Contains(units, m.Unit) {
+				units = append(units, m.Unit)
+			}
+		}
+	}
+
+	return units
+}
+
+// benchmarkTable formats the benchmarks of a package as aligned rows, each unit has its own column.
+func benchmarkTable(benchmarks []Benchmark) []string {
+	units := benchmarkUnits(benchmarks)
+
+	values := make([]map[string]string, len(benchmarks))
+	nameWidth, iterationsWidth := 0, 0
+	widths := make(map[string]int)
+
+	for i, b := range benchmarks {
+		values[i] = make(map[string]string)
+		nameWidth = max(nameWidth, len(b.FullName()))
+		iterationsWidth = max(iterationsWidth, len(strconv.Itoa(b.Iterations)))
+
+		for unit, value := range b.Measurements() {
+			values[i][unit] = formatBenchmarkValue(value)
+			widths[unit] = max(widths[unit], len(values[i][unit]))
+		}
+	}
+
+	rows := make([]string, len(benchmarks))
+	for i, b := range benchmarks {
+		row := color.CyanString("%-*s", nameWidth, b.FullName())
+		row += fmt.Sprintf("  %*d", iterationsWidth, b.Iterations)
+
+		for _, unit := range units {
+			if widths[unit] == 0 {
+				continue
+			}
+
+			value, ok := values[i][unit]
+			if !ok {
+				row += strings.Repeat(" ", widths[unit]+len(unit)+3)
+				continue
+			}
+
+			cell := fmt.Sprintf("%*s %s", widths[unit], value, unit)
+			switch unit {
+			case "ns/op":
+				cell = color.GreenString(cell)
+			case "B/op", "allocs/op":
+				cell = yellow.Sprint(cell)
+			default:
+				cell = blue.Sprint(cell)
+			}
+
+			row += "  " + cell
+		}
+
+		rows[i] = strings.TrimRight(row, " ")
+	}
+
+	return rows
+}
+
+func formatBenchmarkValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
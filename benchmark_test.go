@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBenchmark(t *testing.T) {
+	bytes, allocs := int64(24), int64(2)
+
+	tests := []struct {
+		name string
+		line string
+		want Benchmark
+		ok   bool
+	}{
+		{
+			"without GOMAXPROCS suffix",
+			"BenchmarkJoin        \t    2000\t        79.87 ns/op\n",
+			Benchmark{Name: "BenchmarkJoin", Procs: 1, Iterations: 2000, NsPerOp: 79.87},
+			true,
+		},
+		{
+			"memory",
+			"BenchmarkBuilder/small-8   \t    2000\t       117.9 ns/op\t      24 B/op\t       2 allocs/op",
+			Benchmark{Name: "BenchmarkBuilder/small", Procs: 8, Iterations: 2000, NsPerOp: 117.9, BytesPerOp: &bytes, AllocsPerOp: &allocs},
+			true,
+		},
+		{
+			"custom metrics",
+			"BenchmarkSum256-2   \t    2000\t      1055 ns/op\t 970.16 MB/s\t      1024 bytes/hash",
+			Benchmark{Name: "BenchmarkSum256", Procs: 2, Iterations: 2000, NsPerOp: 1055, Metrics: []BenchmarkMetric{
+				{Unit: "MB/s", Value: 970.16},
+				{Unit: "bytes/hash", Value: 1024},
+			}},
+			true,
+		},
+		{
+			"dash in the name",
+			"BenchmarkSize/size-10-4 \t 100\t 5 ns/op",
+			Benchmark{Name: "BenchmarkSize/size-10", Procs: 4, Iterations: 100, NsPerOp: 5},
+			true,
+		},
+		{"name only", "BenchmarkJoin", Benchmark{}, false},
+		{"missing unit", "BenchmarkJoin-2 \t 2000\t 79.87", Benchmark{}, false},
+		{"not a benchmark", "    main_test.go:10: Benchmark 100 5 ns/op", Benchmark{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			benchmark, ok := ParseBenchmark(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, benchmark)
+		})
+	}
+}
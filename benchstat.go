@@ -0,0 +1,417 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// benchmarkAlpha is the p-value under which a change is considered significant, the same as benchstat.
+const benchmarkAlpha = 0.05
+
+var ErrNoBenchmarks = errors.New("no benchmarks")
+
+// BenchmarkBaseline stores the results of every run of each benchmark, so they can be compared later.
+type BenchmarkBaseline struct {
+	Benchmarks []Benchmark `json:"benchmarks"`
+}
+
+func LoadBenchmarkBaseline(path string) (BenchmarkBaseline, error) {
+	baseline := BenchmarkBaseline{}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return baseline, err
+	}
+
+	err = json.Unmarshal(content, &baseline)
+	return baseline, err
+}
+
+func SaveBenchmarkBaseline(path string, run *Run) error {
+	baseline := BenchmarkBaseline{Benchmarks: run.Benchmarks()}
+	if len(baseline.Benchmarks) == 0 {
+		return ErrNoBenchmarks
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	content, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// BenchmarkComparison compares one unit of a benchmark between the baseline and the current run, using the
+// samples of every run of the benchmark, e.g. with -count.
+type BenchmarkComparison struct {
+	Pkg  string
+	Name string
+	Unit string
+	Old  []float64
+	New  []float64
+}
+
+func (c BenchmarkComparison) OldMedian() float64 {
+	return median(c.Old)
+}
+
+func (c BenchmarkComparison) NewMedian() float64 {
+	return median(c.New)
+}
+
+func (c BenchmarkComparison) Ratio() float64 {
+	if c.OldMedian() == 0 {
+		return 1
+	}
+
+	return c.NewMedian() / c.OldMedian()
+}
+
+// Delta returns the change of the median in percent.
+func (c BenchmarkComparison) Delta() float64 {
+	return (c.Ratio() - 1) * 100
+}
+
+func (c BenchmarkComparison) PValue() float64 {
+	return mannWhitneyU(c.Old, c.New)
+}
+
+// CanBeSignificant reports whether there are enough samples for a change to be significant. Even when every
+// new sample is worse than every old one, the p-value can't be lower than 2 / C(n1+n2, n1), e.g. 0.1 with 3
+// samples on each side.
+func (c BenchmarkComparison) CanBeSignificant() bool {
+	n1, n2 := len(c.Old), len(c.New)
+	if n1 == 0 || n2 == 0 {
+		return false
+	}
+
+	orderings := 1.0
+	for i := 1; i <= n1; i++ {
+		orderings = orderings * float64(n2+i) / float64(i)
+	}
+
+	return 2/orderings < benchmarkAlpha
+}
+
+func (c BenchmarkComparison) Significant() bool {
+	return c.PValue() < benchmarkAlpha && c.Delta() != 0
+}
+
+// Worse reports whether the change is for the worse. Throughputs, like MB/s, are better when higher,
+// every other unit is better when lower.
+func (c BenchmarkComparison) Worse() bool {
+	if strings.HasSuffix(c.Unit, "/s") {
+		return c.Delta() < 0
+	}
+
+	return c.Delta() > 0
+}
+
+// Regressed reports whether the change is a significant regression bigger than the threshold, in percent.
+func (c BenchmarkComparison) Regressed(threshold float64) bool {
+	return c.Significant() && c.Worse() && math.Abs(c.Delta()) > threshold
+}
+
+type benchmarkKey struct {
+	pkg  string
+	name string
+}
+
+// CompareBenchmarks compares the benchmarks that are both in the baseline and the current run, in the order
+// they ran.
+func CompareBenchmarks(baseline []Benchmark, current []Benchmark) []BenchmarkComparison {
+	before, after := groupBenchmarks(baseline), groupBenchmarks(current)
+
+	keys := []benchmarkKey{}
+	for _, b := range current {
+		key := benchmarkKey{b.Pkg, b.FullName()}
+		if _, ok := before[key]; ok && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	comparisons := []BenchmarkComparison{}
+	for _, key := range keys {
+		for _, unit := range benchmarkUnits(slices.Concat(before[key], after[key])) {
+			c := BenchmarkComparison{Pkg: key.pkg, Name: key.name, Unit: unit}
+			c.Old = benchmarkSamples(before[key], unit)
+			c.New = benchmarkSamples(after[key], unit)
+
+			if len(c.Old) > 0 && len(c.New) > 0 {
+				comparisons = append(comparisons, c)
+			}
+		}
+	}
+
+	return comparisons
+}
+
+func groupBenchmarks(benchmarks []Benchmark) map[benchmarkKey][]Benchmark {
+	groups := make(map[benchmarkKey][]Benchmark)
+	for _, b := range benchmarks {
+		key := benchmarkKey{b.Pkg, b.FullName()}
+		groups[key] = append(groups[key], b)
+	}
+
+	return groups
+}
+
+func benchmarkSamples(benchmarks []Benchmark, unit string) []float64 {
+	samples := []float64{}
+	for _, b := range benchmarks {
+		if value, ok := b.Measurements()[unit]; ok {
+			samples = append(samples, value)
+		}
+	}
+
+	return samples
+}
+
+// compareBenchmarks prints how the benchmarks of the run changed from the baseline and returns how many
+// regressed more than the threshold.
+func (p *Processor) compareBenchmarks() (int, error) {
+	baseline, err := LoadBenchmarkBaseline(p.config.BenchCompare)
+	if err != nil {
+		return 0, err
+	}
+
+	comparisons := CompareBenchmarks(baseline.Benchmarks, p.renderer.Run().Benchmarks())
+	if len(comparisons) == 0 {
+		p.renderer.diagnosticf("\n%s\n", yellow.Sprintf("No benchmarks in common with %s", p.config.BenchCompare))
+		return 0, nil
+	}
+
+	p.renderer.diagnosticf("\n%s\n", blue.Sprintf("Benchmarks compared with %s:", p.config.BenchCompare))
+
+	regressed := 0
+	pkg := ""
+	rows := benchmarkComparisonTable(comparisons, p.config.BenchThreshold)
+
+	for i, c := range comparisons {
+		if c.Pkg != pkg {
+			pkg = c.Pkg
+			p.renderer.diagnosticf("\n%s\n", pkg)
+		}
+
+		if p.config.BenchThreshold > 0 && c.Regressed(p.config.BenchThreshold) {
+			regressed++
+		}
+
+		p.renderer.diagnosticf("\t%s\n", rows[i])
+	}
+
+	fewSamples := 0
+	for _, c := range comparisons {
+		if !c.CanBeSignificant() {
+			fewSamples++
+		}
+	}
+
+	if fewSamples > 0 {
+		p.renderer.diagnosticf("\n%s\n", yellow.Sprintf(
+			"%d of %d comparisons have too few samples to ever be significant, so they can't regress. "+
+				"Run the benchmarks with -count=5 or more for both the baseline and the current run",
+			fewSamples, len(comparisons),
+		))
+	}
+
+	return regressed, nil
+}
+
+// benchmarkComparisonTable formats the comparisons as aligned rows. Significant changes are colored, and
+// regressions over the threshold are highlighted.
+func benchmarkComparisonTable(comparisons []BenchmarkComparison, threshold float64) []string {
+	cells := make([][]string, len(comparisons))
+	widths := make([]int, 6)
+
+	for i, c := range comparisons {
+		delta, ratio := "~", "~"
+		if c.Significant() {
+			delta = fmt.Sprintf("%+.2f%%", c.Delta())
+			ratio = fmt.Sprintf("%.2fx", c.Ratio())
+		}
+
+		n := fmt.Sprintf("n=%d", len(c.Old))
+		if len(c.Old) != len(c.New) {
+			n = fmt.Sprintf("n=%d+%d", len(c.Old), len(c.New))
+		}
+
+		cells[i] = []string{
+			c.Name,
+			c.Unit,
+			formatMedian(c.OldMedian()) + " → " + formatMedian(c.NewMedian()),
+			delta,
+			ratio,
+			fmt.Sprintf("(p=%.3f %s)", c.PValue(), n),
+		}
+
+		for j, cell := range cells[i] {
+			widths[j] = max(widths[j], len([]rune(cell)))
+		}
+	}
+
+	rows := make([]string, len(comparisons))
+	for i, c := range comparisons {
+		padded := make([]string, len(cells[i]))
+		for j, cell := range cells[i] {
+			padding := strings.Repeat(" ", widths[j]-len([]rune(cell)))
+
+			// Names and units are aligned to the left, numbers to the right
+			if j < 2 {
+				padded[j] = cell + padding
+			} else {
+				padded[j] = padding + cell
+			}
+		}
+
+		padded[0] = color.CyanString(padded[0])
+
+		change := strings.Join(padded[3:5], "  ")
+		switch {
+		case c.Regressed(threshold) && threshold > 0:
+			change = color.New(color.FgRed, color.Bold).Sprint(change)
+		case c.Significant() && c.Worse():
+			change = red.Sprint(change)
+		case c.Significant():
+			change = color.GreenString(change)
+		}
+
+		rows[i] = strings.Join([]string{padded[0], padded[1], padded[2], change, padded[5]}, "  ")
+	}
+
+	return rows
+}
+
+// formatMedian formats the value with 4 significant digits, which is enough to see the change.
+func formatMedian(value float64) string {
+	if value == math.Trunc(value) {
+		return fmt.Sprintf("%.0f", value)
+	}
+
+	decimals := max(0, 3-int(math.Floor(math.Log10(math.Abs(value)))))
+	return fmt.Sprintf("%.*f", decimals, value)
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
+
+// mannWhitneyU returns the two-sided p-value of the Mann-Whitney U test, which checks whether the samples
+// come from the same distribution without assuming it's normal. Small samples without ties use the exact
+// distribution of U, the others use the normal approximation.
+func mannWhitneyU(x, y []float64) float64 {
+	n1, n2 := len(x), len(y)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	all := make([]float64, 0, n1+n2)
+	all = append(all, x...)
+	all = append(all, y...)
+	slices.Sort(all)
+
+	// Tied values get the average of their ranks
+	ranks := make(map[float64]float64)
+	ties := []int{}
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j] == all[i] {
+			j++
+		}
+
+		ranks[all[i]] = float64(i+j+1) / 2
+		if j-i > 1 {
+			ties = append(ties, j-i)
+		}
+		i = j
+	}
+
+	rankSum := 0.0
+	for _, v := range x {
+		rankSum += ranks[v]
+	}
+
+	u1 := rankSum - float64(n1*(n1+1))/2
+	u := math.Min(u1, float64(n1*n2)-u1)
+
+	if len(ties) == 0 && n1 <= 20 && n2 <= 20 {
+		return math.Min(1, 2*exactUCDF(n1, n2, int(u)))
+	}
+
+	n := float64(n1 + n2)
+	tieCorrection := 0.0
+	for _, t := range ties {
+		tieCorrection += float64(t*t*t - t)
+	}
+
+	sigma := math.Sqrt(float64(n1*n2) / 12 * ((n + 1) - tieCorrection/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+
+	z := (u - float64(n1*n2)/2 + 0.5) / sigma
+	return math.Min(1, math.Erfc(-z/math.Sqrt2))
+}
+
+// exactUCDF returns the probability of U being at most u when both samples come from the same distribution,
+// counting the orderings of the samples that give each U.
+func exactUCDF(n1, n2, u int) float64 {
+	// counts[i][j][k] is the number of orderings of i x's and j y's with U = k
+	counts := make([][][]float64, n1+1)
+	for i := range counts {
+		counts[i] = make([][]float64, n2+1)
+		for j := range counts[i] {
+			counts[i][j] = make([]float64, i*j+1)
+			if i == 0 || j == 0 {
+				counts[i][j][0] = 1
+				continue
+			}
+
+			for k := range counts[i][j] {
+				// The largest value is either an x, greater than all j y's, or a y
+				if k >= j && k-j < len(counts[i-1][j]) {
+					counts[i][j][k] += counts[i-1][j][k-j]
+				}
+				if k < len(counts[i][j-1]) {
+					counts[i][j][k] += counts[i][j-1][k]
+				}
+			}
+		}
+	}
+
+	total, below := 0.0, 0.0
+	for k, count := range counts[n1][n2] {
+		total += count
+		if k <= u {
+			below += count
+		}
+	}
+
+	return below / total
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mannWhitneyU(t *testing.T) {
+	tests := []struct {
+		name string
+		x    []float64
+		y    []float64
+		want float64
+	}{
+		{"separated", []float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 0.0079},
+		{"separated reversed", []float64{6, 7, 8, 9, 10}, []float64{1, 2, 3, 4, 5}, 0.0079},
+		{"interleaved", []float64{1, 3, 5, 7, 9}, []float64{2, 4, 6, 8, 10}, 0.6905},
+		{"single samples", []float64{1}, []float64{2}, 1},
+		{"equal", []float64{2, 2, 2}, []float64{2, 2, 2}, 1},
+		{"ties", []float64{1, 1, 2, 2, 3}, []float64{4, 4, 5, 5, 6}, 0.0112},
+		{"empty", []float64{}, []float64{1, 2}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, mannWhitneyU(tt.x, tt.y), 0.0001)
+		})
+	}
+}
+
+func TestCompareBenchmarks(t *testing.T) {
+	a := assert.New(t)
+
+	bench := func(name string, procs int, ns float64, mbs float64) Benchmark {
+		return Benchmark{Pkg: "pkg", Name: name, Procs: procs, Iterations: 100, NsPerOp: ns, Metrics: []BenchmarkMetric{{Unit: "MB/s", Value: mbs}}}
+	}
+
+	baseline := []Benchmark{}
+	current := []Benchmark{}
+	for i := range 5 {
+		baseline = append(baseline, bench("BenchmarkA", 1, 100+float64(i), 10), bench("BenchmarkA", 2, 50, 20))
+		current = append(current, bench("BenchmarkA", 1, 200+float64(i), 5), bench("BenchmarkA", 2, 50, 20))
+	}
+	current = append(current, bench("BenchmarkNew", 1, 1, 1))
+
+	comparisons := CompareBenchmarks(baseline, current)
+	a.Len(comparisons, 4)
+
+	ns := comparisons[0]
+	a.Equal("BenchmarkA", ns.Name)
+	a.Equal("ns/op", ns.Unit)
+	a.Equal(102.0, ns.OldMedian())
+	a.Equal(202.0, ns.NewMedian())
+	a.InDelta(98.04, ns.Delta(), 0.01)
+	a.True(ns.Significant())
+	a.True(ns.Worse())
+	a.True(ns.Regressed(10))
+	a.False(ns.Regressed(100))
+
+	throughput := comparisons[1]
+	a.Equal("MB/s", throughput.Unit)
+	a.Equal(0.5, throughput.Ratio())
+	a.True(throughput.Worse())
+
+	a.True(ns.CanBeSignificant())
+
+	tests := []struct {
+		old  int
+		new  int
+		want bool
+	}{
+		{1, 1, false},
+		{3, 3, false},
+		{3, 4, false},
+		{3, 5, true},
+		{4, 4, true},
+		{0, 10, false},
+	}
+	for _, tt := range tests {
+		c := BenchmarkComparison{Old: make([]float64, tt.old), New: make([]float64, tt.new)}
+		a.Equal(tt.want, c.CanBeSignificant(), "%d and %d samples", tt.old, tt.new)
+	}
+
+	unchanged := comparisons[2]
+	a.Equal("BenchmarkA-2", unchanged.Name)
+	a.False(unchanged.Significant())
+	a.False(unchanged.Regressed(0))
+}
+
+func Test_compareBenchmarks(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "bench.json")
+
+	processor := NewProcessor(Config{BenchSave: path})
+	processTestdata(t, processor, "benchmark_count.txt")
+
+	baseline, err := LoadBenchmarkBaseline(path)
+	r.NoError(err)
+	r.Len(baseline.Benchmarks, 15)
+
+	t.Run("unchanged", func(t *testing.T) {
+		processor := NewProcessor(Config{BenchCompare: path, BenchThreshold: 10})
+		output := processTestdata(t, processor, "benchmark_count.txt")
+
+		a.Contains(output, `Benchmarks compared with `+path+`:
+
+example.com/bench/strs
+	BenchmarkJoin           ns/op      66.60 → 66.60  ~  ~  (p=1.000 n=5)
+	BenchmarkBuilder/small  ns/op      78.76 → 78.76  ~  ~  (p=1.000 n=5)
+	BenchmarkBuilder/small  B/op             24 → 24  ~  ~  (p=1.000 n=5)
+	BenchmarkBuilder/small  allocs/op          2 → 2  ~  ~  (p=1.000 n=5)
+	BenchmarkBuilder/large  ns/op        4445 → 4445  ~  ~  (p=1.000 n=5)
+	BenchmarkBuilder/large  B/op         3320 → 3320  ~  ~  (p=1.000 n=5)
+	BenchmarkBuilder/large  allocs/op          9 → 9  ~  ~  (p=1.000 n=5)
+`)
+		a.NotContains(output, "regressed")
+		a.NotContains(output, "too few samples")
+	})
+
+	t.Run("too few samples", func(t *testing.T) {
+		single := filepath.Join(t.TempDir(), "single.json")
+		r.NoError(SaveBenchmarkBaseline(single, &Run{Packages: []*PackageRun{{Entry: TestEntry{Benchmarks: baseline.Benchmarks[:1]}}}}))
+
+		processor := NewProcessor(Config{BenchCompare: single, BenchThreshold: 10})
+		output := processTestdata(t, processor, "benchmark_count.txt")
+
+		a.Contains(output, "1 of 1 comparisons have too few samples to ever be significant")
+	})
+
+	t.Run("regressed", func(t *testing.T) {
+		// The baseline was twice as fast
+		for i := range baseline.Benchmarks {
+			if baseline.Benchmarks[i].Name == "BenchmarkJoin" {
+				baseline.Benchmarks[i].NsPerOp /= 2
+			}
+		}
+
+		faster := filepath.Join(t.TempDir(), "faster.json")
+		r.NoError(SaveBenchmarkBaseline(faster, &Run{Packages: []*PackageRun{{Entry: TestEntry{Benchmarks: baseline.Benchmarks}}}}))
+
+		file, err := os.Open(filepath.Join("testdata", "benchmark_count.txt"))
+		r.NoError(err)
+		defer file.Close()
+
+		code := 0
+		output := captureOutput(func() {
+			code = NewProcessor(Config{BenchCompare: faster, BenchThreshold: 10}).Process(file)
+		})
+
+		a.Equal(1, code)
+		a.Contains(output, "\tBenchmarkJoin           ns/op      33.30 → 66.60  +100.00%  2.00x  (p=0.008 n=5)\n")
+		a.Contains(output, "\n1 benchmarks regressed more than 10%\n")
+	})
+}
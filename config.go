@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	PrettyFormat   = "pretty"
+	TAPFormat      = "tap"
+	TeamCityFormat = "teamcity"
+
+	FallbackAll  = "all"
+	FallbackNone = "none"
+)
+
+var (
+	formats   = []string{PrettyFormat, TAPFormat, TeamCityFormat}
+	fallbacks = []string{FallbackAll, FallbackNone}
+
+	ErrInvalidFormat   = errors.New("invalid format")
+	ErrInvalidFallback = errors.New("invalid last failed fallback")
+)
+
+type Config struct {
+	Format             string
+	JUnitFile          string
+	JSONFile           string
+	GitHubActions      bool
+	MarkdownFile       string
+	HTMLFile           string
+	RawFile            string
+	SARIFFile          string
+	CTRFFile           string
+	AllureDir          string
+	RerunFails         int
+	Stream             bool
+	FailFast           bool
+	Slowest            int
+	SlowThreshold      time.Duration
+	SlowExitCode       int
+	LastFailed         bool
+	LastFailedFallback string
+	Shard              Shard
+	TimingsFile        string
+	BenchSave          string
+	BenchCompare       string
+	BenchThreshold     float64
+	CoverageMin        float64
+	CoverageMinPkgs    []CoverageThreshold
+	CoverFuncs         int
+	CoverHTML          string
+	CoverOut           string
+	GoTestArgs         []string
+}
+
+func ParseConfig(args []string) (Config, error) {
+	cfg := Config{}
+
+	fs := flag.NewFlagSet("gotestpp", flag.ContinueOnError)
+	fs.StringVar(&cfg.Format, "format", PrettyFormat, "output `format`, one of: "+strings.Join(formats, ", "))
+	fs.StringVar(&cfg.JUnitFile, "junitfile", "", "write a JUnit XML report to `file`")
+	fs.StringVar(&cfg.JSONFile, "jsonfile", "", "write a JSON report to `file`")
+	fs.StringVar(&cfg.MarkdownFile, "markdownfile", "", "write a Markdown summary to `file`, e.g. $GITHUB_STEP_SUMMARY")
+	fs.StringVar(&cfg.HTMLFile, "htmlfile", "", "write a self-contained HTML report to `file`")
+	fs.StringVar(&cfg.CTRFFile, "ctrffile", "", "write a CTRF JSON report to `file`")
+	fs.StringVar(&cfg.AllureDir, "allure-dir", "", "write Allure results to `dir`")
+	fs.StringVar(&cfg.RawFile, "rawfile", "", "save the raw go test -json output to `file`, gzip compressed if it ends with .gz")
+	fs.StringVar(&cfg.SARIFFile, "sariffile", "", "write a SARIF 2.1.0 log with the failures to `file`")
+	fs.BoolVar(&cfg.FailFast, "fail-fast", false, "stop go test on the first failure, in any package")
+	fs.BoolVar(&cfg.Stream, "stream", false, "print failures as soon as they happen, with a live status line")
+	fs.IntVar(&cfg.Slowest, "slowest", 0, "print the `n` slowest tests and packages after the summary")
+	fs.DurationVar(&cfg.SlowThreshold, "slow-threshold", 0, "highlight tests slower than `duration`, listing all of them when --slowest is not set")
+	fs.IntVar(&cfg.SlowExitCode, "slow-exit-code", 0, "exit with `code` when a test is slower than --slow-threshold and no test failed")
+	fs.IntVar(&cfg.RerunFails, "rerun-fails", 0, "rerun failed tests up to `n` times, the ones that pass are reported as flaky")
+	fs.BoolVar(&cfg.LastFailed, "last-failed", false, "run only the tests that failed in the last run")
+	fs.StringVar(&cfg.LastFailedFallback, "last-failed-fallback", FallbackAll, "what to run with --last-failed when nothing failed, one of: "+strings.Join(fallbacks, ", "))
+	shard := fs.String("shard", "", "run only the packages of shard `i/n`, balanced by the timings of previous runs")
+	fs.StringVar(&cfg.TimingsFile, "timingsfile", "", "store the package timings used by --shard in `file`, defaults to .gotestpp/timings.json in the module")
+	fs.StringVar(&cfg.BenchSave, "bench-save", "", "save the benchmark results to `file`, to be used as a baseline by --bench-compare")
+	fs.StringVar(&cfg.BenchCompare, "bench-compare", "", "compare the benchmark results with the baseline saved in `file`")
+	fs.Float64Var(&cfg.BenchThreshold, "bench-threshold", 0, "fail when a benchmark is significantly worse than the baseline by more than `percent`")
+	fs.Func("coverage-min", "fail when the coverage of a package is below `percent`, adding -cover when no coverage flag is given", func(s string) (err error) {
+		cfg.CoverageMin, err = parsePercent(s)
+		return err
+	})
+	fs.Func("coverage-min-pkg", "fail when the coverage of the packages matching the import path pattern is below the percent, given as `pattern=percent`, e.g. example.com/app/internal/...=80, can be repeated", func(s string) error {
+		threshold, err := ParseCoverageThreshold(s)
+		cfg.CoverageMinPkgs = append(cfg.CoverageMinPkgs, threshold)
+		return err
+	})
+	fs.IntVar(&cfg.CoverFuncs, "cover-funcs", 10, "print the `n` least covered functions of the -coverprofile profile")
+	fs.StringVar(&cfg.CoverHTML, "cover-html", "", "write the source annotated with the coverage of the -coverprofile profile to `file`")
+	fs.StringVar(&cfg.CoverOut, "cover-out", "", "write the profiles merged by gotestpp cover to `file`")
+	fs.BoolVar(&cfg.GitHubActions, "github-actions", os.Getenv("GITHUB_ACTIONS") == "true", "annotate failures using GitHub Actions workflow commands")
+
+	own, rest := splitArgs(fs, args)
+	if err := fs.Parse(own); err != nil {
+		return cfg, err
+	}
+
+	if !slices.Contains(formats, cfg.Format) {
+		err := fmt.Errorf("%w %q, must be one of: %s", ErrInvalidFormat, cfg.Format, strings.Join(formats, ", "))
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+
+	if !slices.Contains(fallbacks, cfg.LastFailedFallback) {
+		err := fmt.Errorf("%w %q, must be one of: %s", ErrInvalidFallback, cfg.LastFailedFallback, strings.Join(fallbacks, ", "))
+		fmt.Fprintln(fs.Output(), err)
+		return cfg, err
+	}
+
+	if *shard != "" {
+		var err error
+		if cfg.Shard, err = ParseShard(*shard); err != nil {
+			fmt.Fprintln(fs.Output(), err)
+			return cfg, err
+		}
+	}
+
+	cfg.GoTestArgs = rest
+
+	return cfg, nil
+}
+
+// splitArgs separates the flags known by gotestpp from the ones that must be passed to go test.
+// gotestpp flags always use a double dash (e.g. --junitfile), so they never clash with go test flags.
+func splitArgs(fs *flag.FlagSet, args []string) (own []string, rest []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "-args" || arg == "--args" {
+			// Everything after -args belongs to the test binary
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		if !strings.HasPrefix(arg, "--") {
+			rest = append(rest, arg)
+			continue
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		f := fs.Lookup(name)
+		if f == nil {
+			rest = append(rest, arg)
+			continue
+		}
+
+		own = append(own, arg)
+
+		if !hasValue && !isBoolFlag(f) && i+1 < len(args) {
+			own = append(own, args[i+1])
+			i++
+		}
+	}
+
+	return own, rest
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+var goTestBoolFlags = []string{
+	"a", "asan", "benchmem", "c", "cover", "failfast", "fullpath", "i", "json", "linkshared",
+	"modcacherw", "msan", "n", "race", "short", "trimpath", "v", "work", "x",
+}
+
+// splitGoTestArgs separates the go test flags from the packages, so the packages can be replaced
+// when only some of them must be tested.
+func splitGoTestArgs(args []string) (flags []string, pkgs []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "-args" || arg == "--args" {
+			flags = append(flags, args[i:]...)
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			pkgs = append(pkgs, arg)
+			continue
+		}
+
+		flags = append(flags, arg)
+
+		name := strings.TrimLeft(arg, "-")
+		if !strings.Contains(name, "=") && !slices.Contains(goTestBoolFlags, name) && i+1 < len(args) {
+			flags = append(flags, args[i+1])
+			i++
+		}
+	}
+
+	return flags, pkgs
+}
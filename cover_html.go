@@ -0,0 +1,138 @@
+package main
+
+import (
+	"cmp"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"slices"
+	"strings"
+)
+
+//go:embed cover_html.html
+var coverHTMLTemplate string
+
+type coverHTML struct {
+	Percent string
+	Files   []coverHTMLFile
+}
+
+type coverHTMLFile struct {
+	Name    string
+	Percent string
+	Missing bool
+	Source  template.HTML
+}
+
+type coverBoundary struct {
+	offset int
+	start  bool
+	count  int
+}
+
+// SaveCoverHTML writes a self-contained HTML page with the source of each file of the profile, where covered
+// statements are green and the ones not covered are red.
+func SaveCoverHTML(path string, profile CoverProfile, resolve func(string) (string, bool)) error {
+	tmpl, err := template.New("coverage").Parse(coverHTMLTemplate)
+	if err != nil {
+		return err
+	}
+
+	page := coverHTML{Percent: fmt.Sprintf("%.1f%%", profile.Percent())}
+
+	for _, name := range profile.Files() {
+		blocks := profile.FileBlocks(name)
+		file := coverHTMLFile{
+			Name:    name,
+			Percent: fmt.Sprintf("%.1f%%", CoverProfile{Blocks: blocks}.Percent()),
+			Missing: true,
+		}
+
+		if source, ok := resolve(name); ok {
+			if content, err := os.ReadFile(source); err == nil {
+				file.Source = annotateSource(content, blocks)
+				file.Missing = false
+			}
+		}
+
+		page.Files = append(page.Files, file)
+	}
+
+	output, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	if err := tmpl.Execute(output, page); err != nil {
+		return err
+	}
+
+	return output.Close()
+}
+
+// annotateSource escapes the source and wraps each block of statements in a span with its count.
+func annotateSource(content []byte, blocks []CoverBlock) template.HTML {
+	lines := []int{0}
+	for i, c := range content {
+		if c == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+
+	offset := func(line, col int) int {
+		if line < 1 || line > len(lines) {
+			return len(content)
+		}
+
+		return min(lines[line-1]+col-1, len(content))
+	}
+
+	boundaries := []coverBoundary{}
+	for _, block := range blocks {
+		if block.NumStmt == 0 {
+			continue
+		}
+
+		boundaries = append(boundaries,
+			coverBoundary{offset: offset(block.StartLine, block.StartCol), start: true, count: block.Count},
+			coverBoundary{offset: offset(block.EndLine, block.EndCol)},
+		)
+	}
+
+	// A block may end where the next one starts, so ends go first to keep the spans well nested
+	slices.SortStableFunc(boundaries, func(a, b coverBoundary) int {
+		if a.offset == b.offset && a.start != b.start {
+			if a.start {
+				return 1
+			}
+			return -1
+		}
+
+		return cmp.Compare(a.offset, b.offset)
+	})
+
+	b := &strings.Builder{}
+	last := 0
+	for _, boundary := range boundaries {
+		b.WriteString(template.HTMLEscapeString(string(content[last:boundary.offset])))
+		last = boundary.offset
+
+		if !boundary.start {
+			b.WriteString("</span>")
+			continue
+		}
+
+		class := "uncovered"
+		if boundary.count > 0 {
+			class = "covered"
+		}
+
+		fmt.Fprintf(b, `<span class="%s" title="%d">`, class, boundary.count)
+	}
+
+	b.WriteString(template.HTMLEscapeString(string(content[last:])))
+
+	return template.HTML(b.String())
+}
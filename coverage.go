@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	coverageRe = regexp.MustCompile(`coverage: (\d+(?:\.\d+)?)% of statements`)
+
+	ErrInvalidCoverageThreshold = errors.New("invalid coverage threshold")
+)
+
+// parseCoverage extracts the coverage of a package from lines like "coverage: 83.1% of statements".
+func parseCoverage(output string) (float64, bool) {
+	matches := coverageRe.FindStringSubmatch(output)
+	if matches == nil {
+		return 0, false
+	}
+
+	coverage, err := strconv.ParseFloat(matches[1], 64)
+	return coverage, err == nil
+}
+
+// CoverageThreshold is the minimum coverage of the packages matching the pattern, which works like the
+// package patterns of go test, e.g. example.com/app/internal/...
+type CoverageThreshold struct {
+	Pattern string
+	Min     float64
+}
+
+func ParseCoverageThreshold(s string) (CoverageThreshold, error) {
+	pattern, value, ok := strings.Cut(s, "=")
+	if !ok || pattern == "" {
+		return CoverageThreshold{}, fmt.Errorf("%w %q, must be pattern=percent, e.g. example.com/app/...=80", ErrInvalidCoverageThreshold, s)
+	}
+
+	minimum, err := parsePercent(value)
+	if err != nil {
+		return CoverageThreshold{}, fmt.Errorf("%w %q: %s", ErrInvalidCoverageThreshold, s, err)
+	}
+
+	return CoverageThreshold{Pattern: pattern, Min: minimum}, nil
+}
+
+// Matches follows the rules of go test, e.g. example.com/app/... matches example.com/app and its subpackages.
+func (t CoverageThreshold) Matches(pkg string) bool {
+	re := strings.ReplaceAll(regexp.QuoteMeta(t.Pattern), `\.\.\.`, `.*`)
+	if prefix, ok := strings.CutSuffix(re, `/.*`); ok {
+		re = prefix + `(/.*)?`
+	}
+
+	matched, _ := regexp.MatchString("^"+re+"$", pkg)
+	return matched
+}
+
+// parsePercent parses a percentage between 0 and 100, the % sign is optional.
+func parsePercent(s string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if value < 0 || value > 100 {
+		return 0, fmt.Errorf("%g is not between 0 and 100", value)
+	}
+
+	return value, nil
+}
+
+// coverageMin returns the minimum coverage of the package, the last matching threshold wins over the global minimum.
+func coverageMin(pkg string, global float64, thresholds []CoverageThreshold) float64 {
+	for _, t := range slices.Backward(thresholds) {
+		if t.Matches(pkg) {
+			return t.Min
+		}
+	}
+
+	return global
+}
+
+// checkCoverage prints the packages with coverage below their minimum and returns how many there are.
+func (p *Processor) checkCoverage() int {
+	run := p.renderer.Run()
+
+	if !slices.ContainsFunc(run.Packages, hasCoverage) {
+		p.renderer.diagnosticf("\n%s\n", yellow.Sprint("No coverage reported, the minimum coverage can't be checked. Run go test with -cover"))
+		return 0
+	}
+
+	lines := []string{}
+	for _, pkg := range run.Packages {
+		if !hasCoverage(pkg) {
+			continue
+		}
+
+		minimum := coverageMin(pkg.Name, p.config.CoverageMin, p.config.CoverageMinPkgs)
+		if *pkg.Entry.Coverage < minimum {
+			lines = append(lines, fmt.Sprintf("\t%5.1f%% < %5.1f%%\t%s", *pkg.Entry.Coverage, minimum, pkg.Name))
+		}
+	}
+
+	if len(lines) == 0 {
+		return 0
+	}
+
+	p.renderer.diagnosticf("\n%s\n", red.Sprint("Coverage below the minimum:"))
+	for _, line := range lines {
+		p.renderer.diagnosticf("%s\n", red.Sprint(line))
+	}
+
+	return len(lines)
+}
+
+// hasCoverage reports whether the package has a coverage that counts. Packages without test files are
+// reported with 0% by go test, which says nothing about how well they are tested.
+func hasCoverage(pkg *PackageRun) bool {
+	return pkg.Entry.Coverage != nil && !pkg.Entry.NoTestFiles
+}
+
+// withCover adds -cover to the go test args, unless a coverage flag is already there.
+func withCover(args []string) []string {
+	if hasCoverFlag(args) {
+		return args
+	}
+
+	flags, pkgs := splitGoTestArgs(args)
+	return goTestArgs(pkgs, flags, "-cover")
+}
+
+func hasCoverFlag(args []string) bool {
+	flags, _ := splitGoTestArgs(args)
+
+	for _, flag := range flags {
+		if flag == "-args" || flag == "--args" {
+			break
+		}
+
+		name, _, _ := strings.Cut(strings.TrimLeft(flag, "-"), "=")
+		if strings.HasPrefix(flag, "-") && slices.Contains([]string{"cover", "coverprofile", "coverpkg", "covermode"}, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatCoverage(t TestEntry) string {
+	if t.Coverage == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("\tcoverage: %.1f%%", *t.Coverage)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCoverageThreshold(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    CoverageThreshold
+		wantErr bool
+	}{
+		{"example.com/app/...=80", CoverageThreshold{Pattern: "example.com/app/...", Min: 80}, false},
+		{"example.com/app=72.5%", CoverageThreshold{Pattern: "example.com/app", Min: 72.5}, false},
+		{"example.com/app", CoverageThreshold{}, true},
+		{"=80", CoverageThreshold{}, true},
+		{"example.com/app=high", CoverageThreshold{}, true},
+		{"example.com/app=101", CoverageThreshold{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			threshold, err := ParseCoverageThreshold(tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCoverageThreshold)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, threshold)
+		})
+	}
+}
+
+func Test_coverageMin(t *testing.T) {
+	thresholds := []CoverageThreshold{
+		{Pattern: "example.com/app/...", Min: 70},
+		{Pattern: "example.com/app/internal/...", Min: 90},
+		{Pattern: "example.com/app/internal/gen", Min: 0},
+	}
+
+	tests := []struct {
+		pkg  string
+		want float64
+	}{
+		{"example.com/app", 70},
+		{"example.com/app/cmd", 70},
+		{"example.com/app/internal/store", 90},
+		{"example.com/app/internal/gen", 0},
+		{"example.com/application", 50},
+		{"example.com/other", 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			assert.Equal(t, tt.want, coverageMin(tt.pkg, 50, thresholds))
+		})
+	}
+}
+
+func Test_withCover(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"no flags", []string{"./..."}, []string{"./...", "-cover"}},
+		{"other flags", []string{"-run", "TestA", "./..."}, []string{"./...", "-run", "TestA", "-cover"}},
+		{"cover", []string{"-cover", "./..."}, []string{"-cover", "./..."}},
+		{"coverprofile", []string{"-coverprofile=cover.out", "./..."}, []string{"-coverprofile=cover.out", "./..."}},
+		{"coverpkg", []string{"-coverpkg", "./...", "./..."}, []string{"-coverpkg", "./...", "./..."}},
+		{"binary args", []string{"./...", "-args", "-cover"}, []string{"./...", "-cover", "-args", "-cover"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, withCover(tt.args))
+		})
+	}
+}
+
+func Test_checkCoverage(t *testing.T) {
+	process := func(config Config, fileName string) (string, int) {
+		file, err := os.Open(filepath.Join("testdata", fileName))
+		require.NoError(t, err)
+		defer file.Close()
+
+		code := 0
+		output := captureOutput(func() {
+			code = NewProcessor(config).Process(file)
+		})
+
+		return output, code
+	}
+
+	t.Run("above the minimum", func(t *testing.T) {
+		output, code := process(Config{CoverageMinPkgs: []CoverageThreshold{{Pattern: "example.com/cov/a", Min: 20}}}, "coverage.txt")
+
+		assert.Equal(t, 0, code)
+		assert.NotContains(t, output, "Coverage below the minimum")
+	})
+
+	t.Run("below the minimum", func(t *testing.T) {
+		output, code := process(Config{CoverageMin: 30}, "coverage.txt")
+
+		assert.Equal(t, 1, code)
+
+		// example.com/cov/c has no test files, its 0% isn't checked
+		assert.Contains(t, output, "\nCoverage below the minimum:\n\t 25.0% <  30.0%\texample.com/cov/a\n")
+		assert.True(t, strings.HasSuffix(output, "\texample.com/cov/a\n"))
+	})
+
+	t.Run("no coverage", func(t *testing.T) {
+		output, code := process(Config{CoverageMin: 30}, "success.txt")
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, output, "No coverage reported")
+	})
+}
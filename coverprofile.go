@@ -0,0 +1,540 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var (
+	coverBlockRe = regexp.MustCompile(`^(.+):(\d+)\.(\d+),(\d+)\.(\d+) (\d+) (\d+)$`)
+
+	ErrInvalidCoverProfile = errors.New("invalid coverage profile")
+)
+
+// CoverProfile is a coverage profile written by go test -coverprofile.
+type CoverProfile struct {
+	Mode   string
+	Blocks []CoverBlock
+}
+
+// CoverBlock is a block of statements, the file is named after the import path of its package, e.g.
+// example.com/app/store/store.go. Lines and columns start at 1 and the end column is exclusive.
+type CoverBlock struct {
+	File      string
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+func LoadCoverProfile(path string) (CoverProfile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return CoverProfile{}, err
+	}
+	defer file.Close()
+
+	return ParseCoverProfile(file)
+}
+
+func ParseCoverProfile(r io.Reader) (CoverProfile, error) {
+	profile := CoverProfile{}
+	scanner := bufio.NewScanner(r)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if mode, ok := strings.CutPrefix(line, "mode: "); ok {
+			// Profiles concatenated by hand repeat the mode line
+			if profile.Mode != "" && profile.Mode != mode {
+				return profile, fmt.Errorf("%w, line %d: mode %s doesn't match %s", ErrInvalidCoverProfile, n, mode, profile.Mode)
+			}
+
+			profile.Mode = mode
+			continue
+		}
+
+		if profile.Mode == "" {
+			return profile, fmt.Errorf("%w, line %d: missing mode line", ErrInvalidCoverProfile, n)
+		}
+
+		matches := coverBlockRe.FindStringSubmatch(line)
+		if matches == nil {
+			return profile, fmt.Errorf("%w, line %d: %q", ErrInvalidCoverProfile, n, line)
+		}
+
+		numbers := make([]int, 6)
+		for i, match := range matches[2:] {
+			number, err := strconv.Atoi(match)
+			if err != nil {
+				return profile, fmt.Errorf("%w, line %d: %s", ErrInvalidCoverProfile, n, err)
+			}
+			numbers[i] = number
+		}
+
+		profile.Blocks = append(profile.Blocks, CoverBlock{
+			File:      matches[1],
+			StartLine: numbers[0],
+			StartCol:  numbers[1],
+			EndLine:   numbers[2],
+			EndCol:    numbers[3],
+			NumStmt:   numbers[4],
+			Count:     numbers[5],
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return profile, err
+	}
+
+	return MergeCoverProfiles(profile)
+}
+
+func SaveCoverProfile(path string, profile CoverProfile) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "mode: %s\n", profile.Mode)
+
+	for _, block := range profile.Blocks {
+		fmt.Fprintf(b, "%s:%d.%d,%d.%d %d %d\n",
+			block.File, block.StartLine, block.StartCol, block.EndLine, block.EndCol, block.NumStmt, block.Count)
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// MergeCoverProfiles merges the profiles of several runs, e.g. the shards of a CI run or the reruns of
+// failed tests. Blocks found in more than one profile, which also happens within a single profile with
+// -coverpkg, are counted once: the counts are added up, or in set mode, the block is covered when any
+// profile covers it.
+func MergeCoverProfiles(profiles ...CoverProfile) (CoverProfile, error) {
+	merged := CoverProfile{}
+	blocks := map[CoverBlock]int{}
+
+	for _, profile := range profiles {
+		if merged.Mode == "" {
+			merged.Mode = profile.Mode
+		} else if profile.Mode != "" && profile.Mode != merged.Mode {
+			return merged, fmt.Errorf("%w, can't merge mode %s with mode %s", ErrInvalidCoverProfile, profile.Mode, merged.Mode)
+		}
+
+		for _, block := range profile.Blocks {
+			key := block
+			key.Count = 0
+
+			i, ok := blocks[key]
+			if !ok {
+				blocks[key] = len(merged.Blocks)
+				merged.Blocks = append(merged.Blocks, block)
+				continue
+			}
+
+			if merged.Mode == "set" {
+				merged.Blocks[i].Count = max(merged.Blocks[i].Count, block.Count)
+			} else {
+				merged.Blocks[i].Count += block.Count
+			}
+		}
+	}
+
+	slices.SortStableFunc(merged.Blocks, func(a, b CoverBlock) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.StartLine, b.StartLine),
+			cmp.Compare(a.StartCol, b.StartCol),
+		)
+	})
+
+	return merged, nil
+}
+
+// Percent returns the percentage of statements covered by the profile.
+func (p CoverProfile) Percent() float64 {
+	statements, covered := 0, 0
+	for _, block := range p.Blocks {
+		statements += block.NumStmt
+		if block.Count > 0 {
+			covered += block.NumStmt
+		}
+	}
+
+	return percent(covered, statements)
+}
+
+// Files returns the files of the profile, sorted by name.
+func (p CoverProfile) Files() []string {
+	files := []string{}
+	for _, block := range p.Blocks {
+		if !slices.Contains(files, block.File) {
+			files = append(files, block.File)
+		}
+	}
+
+	slices.Sort(files)
+	return files
+}
+
+func (p CoverProfile) FileBlocks(file string) []CoverBlock {
+	blocks := []CoverBlock{}
+	for _, block := range p.Blocks {
+		if block.File == file {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks
+}
+
+// CoverFunc is the coverage of a function, e.g. (*Store).Get, found by parsing the source files.
+type CoverFunc struct {
+	File       string
+	Line       int
+	Name       string
+	Statements int
+	Covered    int
+}
+
+func (f CoverFunc) Percent() float64 {
+	return percent(f.Covered, f.Statements)
+}
+
+// CoverFuncs returns the coverage of each function with statements, the resolve function returns the
+// source file of each file of the profile. Files that can't be resolved are left out.
+func CoverFuncs(profile CoverProfile, resolve func(file string) (string, bool)) ([]CoverFunc, error) {
+	funcs := []CoverFunc{}
+
+	for _, file := range profile.Files() {
+		source, ok := resolve(file)
+		if !ok {
+			continue
+		}
+
+		fset := token.NewFileSet()
+		parsed, err := parser.ParseFile(fset, source, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks := profile.FileBlocks(file)
+		for _, decl := range parsed.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+
+			start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+			f := CoverFunc{File: file, Line: start.Line, Name: funcName(fn)}
+
+			for _, block := range blocks {
+				if before(block.StartLine, block.StartCol, start.Line, start.Column) ||
+					before(end.Line, end.Column, block.EndLine, block.EndCol) {
+					continue
+				}
+
+				f.Statements += block.NumStmt
+				if block.Count > 0 {
+					f.Covered += block.NumStmt
+				}
+			}
+
+			if f.Statements > 0 {
+				funcs = append(funcs, f)
+			}
+		}
+	}
+
+	return funcs, nil
+}
+
+// funcName returns the name of the function with its receiver, e.g. (*Store).Get or Store.Len.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	recv := fn.Recv.List[0].Type
+	star, pointer := recv.(*ast.StarExpr)
+	if pointer {
+		recv = star.X
+	}
+
+	// The type parameters of generic receivers are left out, e.g. Set[T]
+	switch t := recv.(type) {
+	case *ast.IndexExpr:
+		recv = t.X
+	case *ast.IndexListExpr:
+		recv = t.X
+	}
+
+	if pointer {
+		return "(*" + types.ExprString(recv) + ")." + fn.Name.Name
+	}
+
+	return types.ExprString(recv) + "." + fn.Name.Name
+}
+
+// leastCoveredFuncs returns the n functions with the lowest coverage, leaving out the fully covered ones.
+// Functions with the same coverage are sorted by the number of statements not covered.
+func leastCoveredFuncs(funcs []CoverFunc, n int) []CoverFunc {
+	funcs = slices.DeleteFunc(slices.Clone(funcs), func(f CoverFunc) bool { return f.Covered == f.Statements })
+
+	slices.SortStableFunc(funcs, func(a, b CoverFunc) int {
+		return cmp.Or(
+			cmp.Compare(a.Percent(), b.Percent()),
+			cmp.Compare(b.Statements-b.Covered, a.Statements-a.Covered),
+		)
+	})
+
+	return funcs[:min(n, len(funcs))]
+}
+
+// coverSourceResolver finds the source files of a profile in the current module. Files outside of it are
+// only found when the profile has their absolute path, which go test does for files given on the command line.
+func coverSourceResolver() func(string) (string, bool) {
+	cwd, _ := os.Getwd()
+	module, moduleErr := FindModule(cwd)
+
+	return func(file string) (string, bool) {
+		if filepath.IsAbs(file) {
+			return file, true
+		}
+
+		if moduleErr != nil {
+			return "", false
+		}
+
+		dir, ok := module.PackageDir(path.Dir(file))
+		if !ok {
+			return "", false
+		}
+
+		return filepath.Join(dir, path.Base(file)), true
+	}
+}
+
+// coverProfilePath returns the file go test writes the coverage profile to, following -outputdir.
+func coverProfilePath(args []string) string {
+	flags, _ := splitGoTestArgs(args)
+	profile, outputDir := "", ""
+
+	for i := 0; i < len(flags); i++ {
+		if flags[i] == "-args" || flags[i] == "--args" {
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(flags[i], "-"), "=")
+		if !hasValue && i+1 < len(flags) && (name == "coverprofile" || name == "outputdir") {
+			value = flags[i+1]
+			i++
+		}
+
+		switch name {
+		case "coverprofile":
+			profile = value
+		case "outputdir":
+			outputDir = value
+		}
+	}
+
+	if profile == "" || outputDir == "" || filepath.IsAbs(profile) {
+		return profile
+	}
+
+	return filepath.Join(outputDir, profile)
+}
+
+// useTempCoverProfile makes go test write the coverage profile to a temporary file when coverage is enabled
+// without -coverprofile, since the total coverage of statements can only be computed from the profile.
+// It returns a function that removes the file.
+func (p *Processor) useTempCoverProfile() func() {
+	if !hasCoverFlag(p.config.GoTestArgs) || coverProfilePath(p.config.GoTestArgs) != "" {
+		return func() {}
+	}
+
+	file, err := os.CreateTemp("", "gotestpp-*.cover")
+	if err != nil {
+		return func() {}
+	}
+	file.Close()
+
+	flags, pkgs := splitGoTestArgs(p.config.GoTestArgs)
+	p.config.GoTestArgs = goTestArgs(pkgs, flags, "-coverprofile="+file.Name())
+	p.tempCoverProfile = file.Name()
+
+	return func() { os.Remove(file.Name()) }
+}
+
+// loadCoverProfile reads the coverage profile written by go test, merges it with the profiles of the reruns
+// and sets the total coverage of the run. It returns false when there is no profile.
+func (p *Processor) loadCoverProfile() (CoverProfile, bool, error) {
+	path := coverProfilePath(p.config.GoTestArgs)
+	if path == "" {
+		return CoverProfile{}, false, nil
+	}
+
+	profile, err := LoadCoverProfile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if path != p.tempCoverProfile {
+			p.renderer.diagnosticf("\n%s\n", yellow.Sprintf("The coverage profile %s was not written", path))
+		}
+		return CoverProfile{}, false, nil
+	}
+	if err != nil {
+		return CoverProfile{}, false, err
+	}
+
+	if len(p.coverProfiles) > 0 {
+		if profile, err = MergeCoverProfiles(append([]CoverProfile{profile}, p.coverProfiles...)...); err != nil {
+			return CoverProfile{}, false, err
+		}
+
+		if err := SaveCoverProfile(path, profile); err != nil {
+			return CoverProfile{}, false, err
+		}
+	}
+
+	if len(profile.Blocks) > 0 {
+		coverage := profile.Percent()
+		p.renderer.Run().Summary.Coverage = &coverage
+	}
+
+	return profile, true, nil
+}
+
+// reportCoverProfile reports the least covered functions of the profile given with -coverprofile.
+func (p *Processor) reportCoverProfile(profile CoverProfile) error {
+	path := coverProfilePath(p.config.GoTestArgs)
+	if path == p.tempCoverProfile {
+		return nil
+	}
+
+	return p.printCoverProfile(path, profile)
+}
+
+// Cover merges the profiles given as arguments, e.g. the ones written by each shard, and reports them
+// like the profile of a run.
+func (p *Processor) Cover() int {
+	paths := p.config.GoTestArgs
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, color.RedString("no coverage profiles given"))
+		return 2
+	}
+
+	profiles := []CoverProfile{}
+	for _, path := range paths {
+		profile, err := LoadCoverProfile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, color.RedString("failed to read %s: %s", path, err))
+			return 1
+		}
+
+		profiles = append(profiles, profile)
+	}
+
+	profile, err := MergeCoverProfiles(profiles...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, color.RedString("failed to merge the coverage profiles: %s", err))
+		return 1
+	}
+
+	name := strings.Join(paths, ", ")
+	if p.config.CoverOut != "" {
+		if err := SaveCoverProfile(p.config.CoverOut, profile); err != nil {
+			fmt.Fprintln(os.Stderr, color.RedString("failed to save the coverage profile: %s", err))
+			return 1
+		}
+
+		name = p.config.CoverOut
+	}
+
+	if err := p.printCoverProfile(name, profile); err != nil {
+		fmt.Fprintln(os.Stderr, color.RedString("failed to report the coverage: %s", err))
+		return 1
+	}
+
+	return 0
+}
+
+func (p *Processor) printCoverProfile(name string, profile CoverProfile) error {
+	resolve := coverSourceResolver()
+
+	if p.config.CoverFuncs > 0 {
+		funcs, err := CoverFuncs(profile, resolve)
+		if err != nil {
+			return err
+		}
+
+		if least := leastCoveredFuncs(funcs, p.config.CoverFuncs); len(least) > 0 {
+			p.renderer.diagnosticf("\n%s\n", blue.Sprint("Least covered functions:"))
+			p.printCoverFuncs(least, resolve)
+		}
+	}
+
+	p.renderer.diagnosticf("\ntotal coverage: %.1f%% of statements in %s\n", profile.Percent(), name)
+
+	if p.config.CoverHTML != "" {
+		return SaveCoverHTML(p.config.CoverHTML, profile, resolve)
+	}
+
+	return nil
+}
+
+func (p *Processor) printCoverFuncs(funcs []CoverFunc, resolve func(string) (string, bool)) {
+	cwd, _ := os.Getwd()
+
+	locations := make([]string, len(funcs))
+	width := 0
+	for i, f := range funcs {
+		file := f.File
+		if source, ok := resolve(f.File); ok {
+			if rel, err := filepath.Rel(cwd, source); err == nil {
+				file = rel
+			}
+		}
+
+		locations[i] = fmt.Sprintf("%s:%d", file, f.Line)
+		width = max(width, len(locations[i]))
+	}
+
+	for i, f := range funcs {
+		line := fmt.Sprintf("\t%5.1f%%\t%-*s  %s", f.Percent(), width, locations[i], f.Name)
+		if f.Covered == 0 {
+			line = red.Sprint(line)
+		}
+
+		p.renderer.diagnosticf("%s\n", line)
+	}
+}
+
+// before reports whether the position of line1 and col1 comes before the one of line2 and col2.
+func before(line1, col1, line2, col2 int) bool {
+	return line1 < line2 || line1 == line2 && col1 < col2
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total) * 100
+}
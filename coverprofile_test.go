@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestCoverProfile(t *testing.T, name string) CoverProfile {
+	profile, err := LoadCoverProfile(filepath.Join("testdata", "coverprofile", name))
+	require.NoError(t, err)
+
+	return profile
+}
+
+func resolveTestSource(file string) (string, bool) {
+	if file != "example.com/calc/calc.go" {
+		return "", false
+	}
+
+	return filepath.Join("testdata", "coverprofile", "calc.go"), true
+}
+
+func TestParseCoverProfile(t *testing.T) {
+	profile := loadTestCoverProfile(t, "count.out")
+
+	assert.Equal(t, "count", profile.Mode)
+	assert.Len(t, profile.Blocks, 10)
+	assert.Equal(t, CoverBlock{File: "example.com/calc/calc.go", StartLine: 12, StartCol: 2, EndLine: 13, EndCol: 1, NumStmt: 1, Count: 3}, profile.Blocks[0])
+	assert.Equal(t, 30.0, profile.Percent())
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing mode", "example.com/calc/calc.go:12.2,13.1 1 3\n"},
+		{"invalid block", "mode: set\nexample.com/calc/calc.go:12.2 1 3\n"},
+		{"different modes", "mode: set\nmode: count\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCoverProfile(strings.NewReader(tt.content))
+			assert.ErrorIs(t, err, ErrInvalidCoverProfile)
+		})
+	}
+}
+
+func TestMergeCoverProfiles(t *testing.T) {
+	count, sign := loadTestCoverProfile(t, "count.out"), loadTestCoverProfile(t, "sign.out")
+
+	t.Run("count", func(t *testing.T) {
+		merged, err := MergeCoverProfiles(count, sign)
+		require.NoError(t, err)
+
+		assert.Len(t, merged.Blocks, 10)
+		assert.Equal(t, 3, merged.Blocks[0].Count)
+		assert.Equal(t, 1, merged.Blocks[4].Count)
+		assert.Equal(t, 50.0, merged.Percent())
+	})
+
+	t.Run("set", func(t *testing.T) {
+		block := CoverBlock{File: "example.com/calc/calc.go", StartLine: 12, StartCol: 2, EndLine: 13, EndCol: 1, NumStmt: 1}
+		covered := block
+		covered.Count = 1
+
+		merged, err := MergeCoverProfiles(
+			CoverProfile{Mode: "set", Blocks: []CoverBlock{covered}},
+			CoverProfile{Mode: "set", Blocks: []CoverBlock{covered, block}},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []CoverBlock{covered}, merged.Blocks)
+	})
+
+	t.Run("different modes", func(t *testing.T) {
+		_, err := MergeCoverProfiles(count, CoverProfile{Mode: "set"})
+		assert.ErrorIs(t, err, ErrInvalidCoverProfile)
+	})
+
+	t.Run("save", func(t *testing.T) {
+		merged, err := MergeCoverProfiles(count, sign)
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "merged.out")
+		require.NoError(t, SaveCoverProfile(path, merged))
+
+		saved, err := LoadCoverProfile(path)
+		require.NoError(t, err)
+		assert.Equal(t, merged, saved)
+	})
+}
+
+func TestCoverFuncs(t *testing.T) {
+	merged, err := MergeCoverProfiles(loadTestCoverProfile(t, "count.out"), loadTestCoverProfile(t, "sign.out"))
+	require.NoError(t, err)
+
+	funcs, err := CoverFuncs(merged, resolveTestSource)
+	require.NoError(t, err)
+
+	file := "example.com/calc/calc.go"
+	assert.Equal(t, []CoverFunc{
+		{File: file, Line: 11, Name: "Add", Statements: 1, Covered: 1},
+		{File: file, Line: 15, Name: "Div", Statements: 3, Covered: 2},
+		{File: file, Line: 23, Name: "Sign", Statements: 4, Covered: 2},
+		{File: file, Line: 34, Name: "(*Calculator).Store", Statements: 1, Covered: 0},
+		{File: file, Line: 38, Name: "Calculator.Recall", Statements: 1, Covered: 0},
+	}, funcs)
+
+	least := leastCoveredFuncs(funcs, 3)
+	assert.Equal(t, []string{"(*Calculator).Store", "Calculator.Recall", "Sign"}, []string{least[0].Name, least[1].Name, least[2].Name})
+}
+
+func Test_coverProfilePath(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no profile", []string{"-cover", "./..."}, ""},
+		{"value after the flag", []string{"-coverprofile", "cover.out", "./..."}, "cover.out"},
+		{"value with equals", []string{"./...", "-coverprofile=cover.out"}, "cover.out"},
+		{"output dir", []string{"-outputdir", "out", "-coverprofile=cover.out"}, filepath.Join("out", "cover.out")},
+		{"binary args", []string{"./...", "-args", "-coverprofile=cover.out"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, coverProfilePath(tt.args))
+		})
+	}
+}
+
+func TestSaveCoverHTML(t *testing.T) {
+	profile := loadTestCoverProfile(t, "count.out")
+	profile.Blocks = append(profile.Blocks, CoverBlock{File: "example.com/other/other.go", StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 1, NumStmt: 1})
+
+	path := filepath.Join(t.TempDir(), "coverage.html")
+	require.NoError(t, SaveCoverHTML(path, profile, resolveTestSource))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, "<h1>Coverage: 27.3% of statements</h1>")
+	assert.Contains(t, html, "example.com/calc/calc.go (30.0%)")
+	assert.Contains(t, html, "\t<span class=\"covered\" title=\"3\">return a + b\n</span>}")
+	assert.Contains(t, html, "\t\t<span class=\"uncovered\" title=\"0\">return 0, ErrDivByZero\n</span>\t}")
+	assert.Contains(t, html, `errors.New(&#34;division by zero&#34;)`)
+	assert.Contains(t, html, "The source of example.com/other/other.go was not found.")
+}
+
+func Test_process_totalCoverage(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	jsonPath := filepath.Join(t.TempDir(), "report.json")
+	config := Config{
+		JSONFile:   jsonPath,
+		GoTestArgs: []string{"-coverprofile", filepath.Join("testdata", "coverprofile", "cov.out"), "./..."},
+	}
+
+	output := processTestdata(t, NewProcessor(config), "coverage.txt")
+
+	// The packages have 25% and 100%, but a has 4 statements and b has 6
+	a.Contains(output, "\n3 tests\ncoverage: 70.0% of statements\n")
+	a.Contains(output, "\ntotal coverage: 70.0% of statements in testdata/coverprofile/cov.out\n")
+
+	content, err := os.ReadFile(jsonPath)
+	r.NoError(err)
+
+	var report jsonReport
+	r.NoError(json.Unmarshal(content, &report))
+	r.NotNil(report.Summary.Coverage)
+	a.InDelta(70.0, *report.Summary.Coverage, 0.001)
+}
+
+func Test_useTempCoverProfile(t *testing.T) {
+	t.Run("coverage enabled", func(t *testing.T) {
+		p := NewProcessor(Config{GoTestArgs: []string{"-cover", "./..."}})
+
+		remove := p.useTempCoverProfile()
+
+		assert.NotEmpty(t, p.tempCoverProfile)
+		assert.Equal(t, []string{"./...", "-cover", "-coverprofile=" + p.tempCoverProfile}, p.config.GoTestArgs)
+		assert.FileExists(t, p.tempCoverProfile)
+
+		remove()
+		assert.NoFileExists(t, p.tempCoverProfile)
+	})
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no coverage", []string{"./..."}},
+		{"coverprofile", []string{"-coverprofile=cover.out", "./..."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProcessor(Config{GoTestArgs: tt.args})
+
+			p.useTempCoverProfile()()
+
+			assert.Empty(t, p.tempCoverProfile)
+			assert.Equal(t, tt.args, p.config.GoTestArgs)
+		})
+	}
+}
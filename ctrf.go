@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/joaopsramos/gotestpp/utils"
+)
+
+// CTRFReporter writes a Common Test Report Format report, see https://ctrf.io/docs/specification/overview
+type CTRFReporter struct {
+	path string
+}
+
+type ctrfReport struct {
+	ReportFormat string      `json:"reportFormat"`
+	SpecVersion  string      `json:"specVersion"`
+	Results      ctrfResults `json:"results"`
+}
+
+type ctrfResults struct {
+	Tool    ctrfTool    `json:"tool"`
+	Summary ctrfSummary `json:"summary"`
+	Tests   []ctrfTest  `json:"tests"`
+}
+
+type ctrfTool struct {
+	Name string `json:"name"`
+}
+
+type ctrfSummary struct {
+	Tests   int   `json:"tests"`
+	Passed  int   `json:"passed"`
+	Failed  int   `json:"failed"`
+	Pending int   `json:"pending"`
+	Skipped int   `json:"skipped"`
+	Other   int   `json:"other"`
+	Start   int64 `json:"start"`
+	Stop    int64 `json:"stop"`
+}
+
+type ctrfTest struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Duration int64  `json:"duration"`
+	Start    int64  `json:"start,omitempty"`
+	Stop     int64  `json:"stop,omitempty"`
+	Suite    string `json:"suite"`
+	Message  string `json:"message,omitempty"`
+	Trace    string `json:"trace,omitempty"`
+	Flaky    bool   `json:"flaky,omitempty"`
+}
+
+var ctrfStatuses = map[string]string{
+	"pass": "passed",
+	"fail": "failed",
+	"skip": "skipped",
+}
+
+func NewCTRFReporter(path string) *CTRFReporter {
+	return &CTRFReporter{path: path}
+}
+
+func (c *CTRFReporter) Report(run *Run) error {
+	report := ctrfReport{
+		ReportFormat: "CTRF",
+		SpecVersion:  "0.0.0",
+		Results: ctrfResults{
+			Tool: ctrfTool{Name: "gotestpp"},
+			Summary: ctrfSummary{
+				Tests:   run.Summary.Total(),
+				Passed:  run.Summary.Passed + run.Summary.Flaky,
+				Failed:  run.Summary.Failed,
+				Skipped: run.Summary.Skipped,
+				Start:   unixMilli(run.Start()),
+				Stop:    unixMilli(run.End()),
+			},
+			Tests: []ctrfTest{},
+		},
+	}
+
+	for _, pkg := range run.Packages {
+		for _, t := range pkg.AllTests() {
+			report.Results.Tests = append(report.Results.Tests, c.buildTest(t))
+		}
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, append(output, '\n'), 0o644)
+}
+
+func (c *CTRFReporter) buildTest(t TestEntry) ctrfTest {
+	status, ok := ctrfStatuses[t.Status()]
+	if !ok {
+		status = "other"
+	}
+
+	result := ctrfTest{
+		Name:     t.Name,
+		Status:   status,
+		Duration: int64(t.Elapsed * 1000),
+		Start:    unixMilli(t.Start),
+		Stop:     unixMilli(t.End),
+		Suite:    t.Pkg,
+		Flaky:    t.Flaky,
+	}
+
+	switch t.Action {
+	case "fail":
+		if locations := TestLocations(t); len(locations) > 0 {
+			result.Message = locations[0].Message
+		}
+		result.Trace = utils.StripANSI(formatOutput(t))
+
+	case "skip":
+		result.Message = t.SkipReason()
+	}
+
+	return result
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixMilli()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ctrfReport(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "ctrf.json")
+	processTestdata(t, NewProcessor(Config{CTRFFile: path}), "fail_with_skip.txt")
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	var report ctrfReport
+	r.NoError(json.Unmarshal(content, &report))
+
+	a.Equal("CTRF", report.ReportFormat)
+	a.Equal("gotestpp", report.Results.Tool.Name)
+
+	summary := report.Results.Summary
+	a.Equal(102, summary.Tests)
+	a.Equal(100, summary.Passed)
+	a.Equal(1, summary.Failed)
+	a.Equal(1, summary.Skipped)
+	a.Equal(mustParseTime(t, "2025-04-01T20:11:54.853413754-03:00").UnixMilli(), summary.Start)
+	a.Greater(summary.Stop, summary.Start)
+
+	tests := map[string]ctrfTest{}
+	for _, test := range report.Results.Tests {
+		tests[test.Name] = test
+	}
+
+	failed := tests["TestPostgresExpense_GetSummary"]
+	a.Equal("failed", failed.Status)
+	a.Equal(int64(10), failed.Duration)
+	a.Equal("github.com/joaopsramos/fincon/internal/service", failed.Suite)
+	a.Equal("Not equal:\nexpected: 1\nactual  : 2", failed.Message)
+	a.Contains(failed.Trace, "Error Trace:\n\t\t/home/joao/www/fincon/backend/internal/service/expense_test.go:201")
+	a.NotZero(failed.Start)
+
+	skipped := tests["TestExpenseService_Create"]
+	a.Equal("skipped", skipped.Status)
+	a.Equal("expense_test.go:205", skipped.Message)
+
+	a.Equal("passed", tests["TestPostgresExpense_GetSummary/should_return_zero_values_for_two_months_ago"].Status)
+}
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	require.NoError(t, err)
+
+	return parsed
+}
+
+func Test_ctrfReportFlaky(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	processor := NewProcessor(Config{})
+	processTestdata(t, processor, "fail.txt")
+
+	captureOutput(func() {
+		processor.markFlaky([]*rerunTest{{pkg: "github.com/joaopsramos/fincon/internal/service", name: "TestExpenseService_Create", attempt: 1}})
+	})
+
+	path := filepath.Join(t.TempDir(), "ctrf.json")
+	r.NoError(NewCTRFReporter(path).Report(processor.renderer.Run()))
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	var report ctrfReport
+	r.NoError(json.Unmarshal(content, &report))
+
+	summary := report.Results.Summary
+	a.Equal(0, summary.Failed)
+	a.Equal(summary.Tests, summary.Passed+summary.Failed+summary.Skipped+summary.Pending+summary.Other)
+
+	for _, test := range report.Results.Tests {
+		if test.Name == "TestExpenseService_Create" {
+			a.Equal("passed", test.Status)
+			a.True(test.Flaky)
+		} else {
+			a.False(test.Flaky, test.Name)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"unicode"
+	"unicode/utf8"
+)
+
+const fuzzCorpusHeader = "go test fuzz v1"
+
+var ErrInvalidFuzzInput = errors.New("invalid fuzz input")
+
+// fuzzCorpusInput returns the corpus file of a test, relative to its package, when the test runs an entry of
+// the corpus of a fuzz target, e.g. FuzzParse/582528ddfad69eb5. Entries added with f.Add are named seed#N.
+func fuzzCorpusInput(name string) string {
+	root, entry, ok := strings.Cut(name, "/")
+	if !ok || !isFuzzTarget(root) || strings.Contains(entry, "/") || strings.HasPrefix(entry, "seed#") {
+		return ""
+	}
+
+	return path.Join("testdata/fuzz", root, entry)
+}
+
+// isFuzzTarget follows the rules of go test, the name after Fuzz can't start with a lowercase letter.
+func isFuzzTarget(name string) bool {
+	rest, ok := strings.CutPrefix(name, "Fuzz")
+	if !ok {
+		return false
+	}
+
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || !unicode.IsLower(r)
+}
+
+// DecodeFuzzInput decodes a corpus file, returning the type and value of each argument of the fuzz target.
+func DecodeFuzzInput(content string) ([]string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != fuzzCorpusHeader {
+		return nil, fmt.Errorf("%w, missing %q header", ErrInvalidFuzzInput, fuzzCorpusHeader)
+	}
+
+	values := []string{}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		expr, err := parser.ParseExpr(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFuzzInput, err)
+		}
+
+		call, ok := expr.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFuzzInput, line)
+		}
+
+		value := types.ExprString(call.Args[0])
+
+		// Strings and byte slices are quoted again, which turns escapes of printable characters into the characters
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if s, err := strconv.Unquote(lit.Value); err == nil {
+				value = strconv.Quote(s)
+			}
+		}
+
+		values = append(values, types.ExprString(call.Fun)+": "+value)
+	}
+
+	return values, scanner.Err()
+}
+
+// formatFuzzInput formats the failing input of a fuzz test along with the command to run it again.
+func formatFuzzInput(t TestEntry) string {
+	return styledFuzzInput(t, terminalStyle)
+}
+
+func styledFuzzInput(t TestEntry, style outputStyle) string {
+	lines := []string{}
+
+	if t.FuzzProgress != "" {
+		lines = append(lines, "\t"+style.color(color.FgBlue, "Fuzzing: ")+style.text(t.FuzzProgress))
+	}
+
+	file, found := fuzzInputFile(t.Pkg, t.FuzzInput)
+	lines = append(lines, "\t"+style.color(color.FgBlue, "Failing input: ")+style.text(file))
+
+	if found {
+		if content, err := os.ReadFile(file); err == nil {
+			values, err := DecodeFuzzInput(string(content))
+			if err != nil {
+				values = strings.Split(strings.TrimSpace(string(content)), "\n")[1:]
+			}
+
+			for _, value := range values {
+				lines = append(lines, "\t\t"+style.text(value))
+			}
+		}
+	}
+
+	name := t.RootTestName() + "/" + path.Base(t.FuzzInput)
+	lines = append(lines, "\t"+style.color(color.FgBlue, "To reproduce: ")+style.text(fmt.Sprintf("go test -run=%s %s", name, t.Pkg)))
+
+	return strings.Join(lines, "\n")
+}
+
+// fuzzInputFile returns the path of the corpus file relative to the current directory. When the package is
+// not part of the current module, the path relative to the package is returned.
+func fuzzInputFile(pkg string, input string) (string, bool) {
+	cwd, _ := os.Getwd()
+
+	module, err := FindModule(cwd)
+	if err != nil {
+		return input, false
+	}
+
+	dir, ok := module.PackageDir(pkg)
+	if !ok {
+		return input, false
+	}
+
+	file := filepath.Join(dir, filepath.FromSlash(input))
+	if rel, err := filepath.Rel(cwd, file); err == nil {
+		file = rel
+	}
+
+	return file, true
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fuzzCorpusInput(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"FuzzReverse/c90c653d73cb07c7", "testdata/fuzz/FuzzReverse/c90c653d73cb07c7"},
+		{"Fuzz/custom_entry", "testdata/fuzz/Fuzz/custom_entry"},
+		{"FuzzReverse/seed#0", ""},
+		{"FuzzReverse", ""},
+		{"FuzzyMatch/c90c653d73cb07c7", ""},
+		{"TestReverse/c90c653d73cb07c7", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fuzzCorpusInput(tt.name))
+		})
+	}
+}
+
+func TestDecodeFuzzInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			"values",
+			"go test fuzz v1\nstring(\"FUZ0\")\nint(-109)\n[]byte(\"\\x00\\x41\")\nbool(true)\nrune('x')\n",
+			[]string{`string: "FUZ0"`, "int: -109", `[]byte: "\x00A"`, "bool: true", "rune: 'x'"},
+			false,
+		},
+		{"missing header", "string(\"FUZ0\")\n", nil, true},
+		{"invalid value", "go test fuzz v1\nstring(\n", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := DecodeFuzzInput(tt.content)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidFuzzInput)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, values)
+		})
+	}
+}
+
+func Test_formatFuzzInput(t *testing.T) {
+	color.NoColor = true
+
+	dir := t.TempDir()
+	corpus := filepath.Join(dir, "parse", "testdata", "fuzz", "FuzzReverse")
+	require.NoError(t, os.MkdirAll(corpus, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/fuzz\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(corpus, "c90c653d73cb07c7"), []byte("go test fuzz v1\nstring(\"FUZ0\")\nint(-109)\n"), 0o644))
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		os.Chdir(cwd)
+	})
+
+	test := TestEntry{
+		Pkg:          "example.com/fuzz/parse",
+		Name:         "FuzzReverse",
+		FuzzInput:    "testdata/fuzz/FuzzReverse/c90c653d73cb07c7",
+		FuzzProgress: "elapsed: 3s, execs: 130623 (43529/sec), new interesting: 9 (total: 10)",
+	}
+
+	assert.Equal(t, `	Fuzzing: elapsed: 3s, execs: 130623 (43529/sec), new interesting: 9 (total: 10)
+	Failing input: parse/testdata/fuzz/FuzzReverse/c90c653d73cb07c7
+		string: "FUZ0"
+		int: -109
+	To reproduce: go test -run=FuzzReverse/c90c653d73cb07c7 example.com/fuzz/parse`, formatFuzzInput(test))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type GitHubReporter struct {
+	resolver *PathResolver
+}
+
+func NewGitHubReporter(resolver *PathResolver) *GitHubReporter {
+	return &GitHubReporter{resolver: resolver}
+}
+
+func (g *GitHubReporter) Report(run *Run) error {
+	for _, pkg := range run.Packages {
+		if pkg.Entry.BuildFailed {
+			g.annotateBuild(run, pkg)
+			continue
+		}
+
+		for _, t := range pkg.AllTests() {
+			if t.Status() == "fail" {
+				g.annotateTest(t)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *GitHubReporter) annotateTest(t TestEntry) {
+	locations := TestLocations(t)
+
+	if len(locations) == 0 {
+		// Parent tests usually fail only because of their subtests, which are annotated on their own
+		if len(t.FilterSubTestsByAction("fail")) == 0 {
+			g.annotate(t.Pkg, Location{Message: "Test failed"}, t.Name)
+		}
+		return
+	}
+
+	for _, location := range locations {
+		title := t.Name
+		switch location.Kind {
+		case PanicLocation:
+			title += " panicked"
+		case TimeoutLocation:
+			title += " timed out"
+		}
+
+		g.annotate(t.Pkg, location, title)
+	}
+}
+
+func (g *GitHubReporter) annotateBuild(run *Run, pkg *PackageRun) {
+	title := "Build failed: " + pkg.Name
+	locations := BuildLocations(run.BuildOutput(pkg.Name))
+
+	if len(locations) == 0 {
+		g.annotate(pkg.Name, Location{Message: "Build failed"}, title)
+		return
+	}
+
+	for _, location := range locations {
+		g.annotate(pkg.Name, location, title)
+	}
+}
+
+func (g *GitHubReporter) annotate(pkg string, location Location, title string) {
+	properties := []string{}
+
+	if file, ok := g.resolver.Resolve(pkg, location.File); ok {
+		properties = append(properties, "file="+escapeGitHubProperty(file))
+
+		if location.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", location.Line))
+		}
+	}
+
+	properties = append(properties, "title="+escapeGitHubProperty(title))
+
+	fmt.Printf("::error %s::%s\n", strings.Join(properties, ","), escapeGitHubData(location.Message))
+}
+
+// See https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_gitHubAnnotations(t *testing.T) {
+	resolver := &PathResolver{
+		module:    Module{Path: "github.com/joaopsramos/fincon", Dir: "/work/fincon/backend"},
+		workspace: "/work/fincon",
+		cwd:       "/work/fincon/backend",
+	}
+
+	tests := []struct {
+		name     string
+		fileName string
+		want     string
+	}{
+		{
+			"fail",
+			"fail.txt",
+			"::error file=backend/internal/service/expense_test.go,line=213,title=TestExpenseService_Create::2 should be equal to 1\n",
+		},
+		{
+			"testify fail with message",
+			"testify_fail_message.txt",
+			"::error file=backend/internal/service/expense_test.go,line=213,title=TestExpenseService_Create::" +
+				`Not equal:%0Aexpected: "one\ntwo\nthree\nfour"%0Aactual  : "one\nthree\nfour\ntwo"%0A%0ADiff:%0A--- Expected%0A+++ Actual%0A@@ -1,4 +1,4 @@%0A one%0A-two%0A three%0A four%0A+two%0ASome strange%0A	message%0Ahere` + "\n",
+		},
+		{
+			"panic",
+			"panic.txt",
+			"::error file=backend/internal/service/expense_test.go,line=33,title=TestPostgresExpense_GetSummary panicked::panic: something went really wrong [recovered]\n",
+		},
+		{
+			"build failed",
+			"build_failed.txt",
+			"::error file=backend/internal/service/expense_test.go,line=203,title=Build failed%3A github.com/joaopsramos/fincon/internal/service::undefined: pan\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewProcessor(Config{})
+			processor.reporters = []Reporter{NewGitHubReporter(resolver)}
+
+			output := processTestdata(t, processor, tt.fileName)
+
+			assert.Contains(t, output, tt.want)
+		})
+	}
+}
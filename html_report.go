@@ -0,0 +1,151 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/joaopsramos/gotestpp/utils"
+)
+
+//go:embed html_report.html
+var htmlReportTemplate string
+
+type HTMLReporter struct {
+	path string
+}
+
+type htmlReport struct {
+	Summary  string
+	Total    int
+	Passed   int
+	Failed   int
+	Skipped  int
+	Packages []htmlPackage
+	Errors   string
+}
+
+type htmlPackage struct {
+	Name        string
+	Status      string
+	Elapsed     string
+	BuildOutput string
+	Tests       []htmlTest
+}
+
+type htmlTest struct {
+	Name     string
+	FullName string
+	Status   string
+	Elapsed  string
+	Output   template.HTML
+	SubTests []htmlTest
+}
+
+func NewHTMLReporter(path string) *HTMLReporter {
+	return &HTMLReporter{path: path}
+}
+
+func (h *HTMLReporter) Report(run *Run) error {
+	tmpl, err := template.New("report").Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+
+	report := htmlReport{
+		Summary: utils.StripANSI(run.Summary.String()),
+		Total:   run.Summary.Total(),
+		Passed:  run.Summary.Passed + run.Summary.Flaky,
+		Failed:  run.Summary.Failed,
+		Skipped: run.Summary.Skipped,
+		Errors:  strings.Join(run.Errors, "\n"),
+	}
+
+	for _, pkg := range run.Packages {
+		report.Packages = append(report.Packages, h.buildPackage(run, pkg))
+	}
+
+	file, err := os.Create(h.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := tmpl.Execute(file, report); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func (h *HTMLReporter) buildPackage(run *Run, pkg *PackageRun) htmlPackage {
+	result := htmlPackage{Name: pkg.Name, Status: pkg.Status(), Elapsed: fmt.Sprintf("%.2fs", pkg.Entry.Elapsed)}
+
+	switch {
+	case pkg.Entry.BuildFailed:
+		result.Elapsed = "[build failed]"
+		result.BuildOutput = run.BuildOutput(pkg.Name)
+	case pkg.Entry.NoTestFiles:
+		result.Elapsed = "[no test files]"
+	case pkg.Entry.Cached:
+		result.Elapsed = "(cached)"
+	}
+
+	for _, t := range pkg.Tests {
+		result.Tests = append(result.Tests, h.buildTest(t, t))
+	}
+
+	return result
+}
+
+func (h *HTMLReporter) buildTest(root TestEntry, t TestEntry) htmlTest {
+	result := htmlTest{
+		Name:     path.Base(t.Name),
+		FullName: t.Name,
+		Status:   t.Status(),
+		Elapsed:  fmt.Sprintf("%.2fs", t.Elapsed),
+	}
+
+	switch t.Action {
+	case "fail":
+		result.Output = htmlOutput(t)
+	case "skip":
+		result.Output = template.HTML(template.HTMLEscapeString(t.SkipReason()))
+	}
+
+	for _, st := range root.DirectSubTests(t.Name) {
+		result.SubTests = append(result.SubTests, h.buildTest(root, st))
+	}
+
+	return result
+}
+
+var htmlColors = map[color.Attribute]string{
+	color.FgRed:   "red",
+	color.FgGreen: "green",
+	color.FgCyan:  "cyan",
+	color.FgBlue:  "blue",
+}
+
+var htmlStyle = outputStyle{
+	text: template.HTMLEscapeString,
+	color: func(attr color.Attribute, s string) string {
+		return fmt.Sprintf(`<span class="%s">%s</span>`, htmlColors[attr], template.HTMLEscapeString(s))
+	},
+}
+
+// htmlOutput formats the details of a test like the terminal does, using spans instead of ANSI colors.
+func htmlOutput(t TestEntry) template.HTML {
+	output := styledDetails(t, htmlStyle)
+
+	if t.Unfinished {
+		state := htmlStyle.color(color.FgRed, fmt.Sprintf("Did not finish (%s, ran for %.2fs)", unfinishedState(t), t.Elapsed))
+		output = strings.TrimSuffix(state+"\n"+output, "\n")
+	}
+
+	return template.HTML(output)
+}
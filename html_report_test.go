@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_htmlReport(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	processTestdata(t, NewProcessor(Config{HTMLFile: path}), "testify_fail.txt")
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	output := string(content)
+	a.Contains(output, `<details class="test" data-status="fail" data-name="TestPostgresExpense_GetSummary/should_handle_next_month_with_carried_over_excesses" open>`)
+	a.Contains(output, `<span class="cyan">expense_test.go:97:</span>`)
+	a.Contains(output, "\t\t<span class=\"green\">+three</span>\n\t\t two\n\t\t<span class=\"red\">-three</span>\n")
+	a.Contains(output, `expected: &#34;\tone\ntwo\nthree\n\tfour&#34;`)
+	a.NotContains(output, "<script src=")
+	a.NotContains(output, "<link ")
+}
+
+func Test_htmlReportDetails(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		want     string
+	}{
+		{"did not finish", "did_not_finish.txt", `<span class="red">Did not finish (running, ran for`},
+		{"fuzz input", "fuzz.txt", `<span class="blue">Failing input: </span>testdata/fuzz/FuzzReverse/`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.html")
+			processTestdata(t, NewProcessor(Config{HTMLFile: path}), tt.fileName)
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), tt.want)
+		})
+	}
+}
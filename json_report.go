@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// jsonReportVersion must be bumped whenever a field is renamed or removed from the report.
+const jsonReportVersion = 1
+
+type JSONReporter struct {
+	path string
+}
+
+type jsonReport struct {
+	Version  int           `json:"version"`
+	Summary  jsonSummary   `json:"summary"`
+	Packages []jsonPackage `json:"packages"`
+	Errors   []string      `json:"errors,omitempty"`
+	Unparsed []string      `json:"unparsed,omitempty"`
+}
+
+type jsonSummary struct {
+	Total    int      `json:"total"`
+	Passed   int      `json:"passed"`
+	Failed   int      `json:"failed"`
+	Skipped  int      `json:"skipped"`
+	Flaky    int      `json:"flaky,omitempty"`
+	Aborted  int      `json:"aborted,omitempty"`
+	Elapsed  float64  `json:"elapsed"`
+	Coverage *float64 `json:"coverage,omitempty"`
+}
+
+type jsonPackage struct {
+	Name        string          `json:"name"`
+	Status      string          `json:"status"`
+	Elapsed     float64         `json:"elapsed"`
+	Cached      bool            `json:"cached"`
+	NoTestFiles bool            `json:"noTestFiles"`
+	BuildFailed bool            `json:"buildFailed"`
+	BuildOutput string          `json:"buildOutput,omitempty"`
+	Coverage    *float64        `json:"coverage,omitempty"`
+	Tests       []jsonTest      `json:"tests"`
+	Benchmarks  []jsonBenchmark `json:"benchmarks,omitempty"`
+}
+
+type jsonTest struct {
+	Name       string       `json:"name"`
+	Status     string       `json:"status"`
+	Elapsed    float64      `json:"elapsed"`
+	Panicked   bool         `json:"panicked"`
+	Unfinished bool         `json:"unfinished,omitempty"`
+	Flaky      bool         `json:"flaky,omitempty"`
+	SkipReason string       `json:"skipReason,omitempty"`
+	FuzzInput  string       `json:"fuzzInput,omitempty"`
+	Asserts    []jsonAssert `json:"asserts,omitempty"`
+	Output     string       `json:"output,omitempty"`
+	SubTests   []jsonTest   `json:"subTests,omitempty"`
+}
+
+type jsonBenchmark struct {
+	Name        string             `json:"name"`
+	Procs       int                `json:"procs"`
+	Iterations  int                `json:"iterations"`
+	NsPerOp     float64            `json:"nsPerOp"`
+	BytesPerOp  *int64             `json:"bytesPerOp,omitempty"`
+	AllocsPerOp *int64             `json:"allocsPerOp,omitempty"`
+	Metrics     map[string]float64 `json:"metrics,omitempty"`
+}
+
+type jsonAssert struct {
+	Error    string   `json:"error"`
+	Messages string   `json:"messages,omitempty"`
+	Trace    []string `json:"trace"`
+	Test     string   `json:"test,omitempty"`
+}
+
+func NewJSONReporter(path string) *JSONReporter {
+	return &JSONReporter{path: path}
+}
+
+func (j *JSONReporter) Report(run *Run) error {
+	report := jsonReport{
+		Version: jsonReportVersion,
+		Summary: jsonSummary{
+			Total:    run.Summary.Total(),
+			Passed:   run.Summary.Passed,
+			Failed:   run.Summary.Failed,
+			Skipped:  run.Summary.Skipped,
+			Flaky:    run.Summary.Flaky,
+			Aborted:  run.Summary.Aborted,
+			Elapsed:  run.Summary.Elapsed,
+			Coverage: run.Summary.Coverage,
+		},
+		Packages: []jsonPackage{},
+		Errors:   run.Errors,
+		Unparsed: run.Unparsed,
+	}
+
+	for _, pkg := range run.Packages {
+		report.Packages = append(report.Packages, j.buildPackage(run, pkg))
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(j.path, append(output, '\n'), 0o644)
+}
+
+func (j *JSONReporter) buildPackage(run *Run, pkg *PackageRun) jsonPackage {
+	result := jsonPackage{
+		Name:        pkg.Name,
+		Status:      pkg.Status(),
+		Elapsed:     pkg.Entry.Elapsed,
+		Cached:      pkg.Entry.Cached,
+		NoTestFiles: pkg.Entry.NoTestFiles,
+		BuildFailed: pkg.Entry.BuildFailed,
+		Coverage:    pkg.Entry.Coverage,
+		Tests:       []jsonTest{},
+	}
+
+	if pkg.Entry.BuildFailed {
+		result.BuildOutput = run.BuildOutput(pkg.Name)
+	}
+
+	for _, t := range pkg.Tests {
+		result.Tests = append(result.Tests, j.buildTest(t, t))
+	}
+
+	for _, b := range pkg.Entry.Benchmarks {
+		benchmark := jsonBenchmark{
+			Name:        b.Name,
+			Procs:       b.Procs,
+			Iterations:  b.Iterations,
+			NsPerOp:     b.NsPerOp,
+			BytesPerOp:  b.BytesPerOp,
+			AllocsPerOp: b.AllocsPerOp,
+		}
+
+		for _, m := range b.Metrics {
+			if benchmark.Metrics == nil {
+				benchmark.Metrics = make(map[string]float64)
+			}
+			benchmark.Metrics[m.Unit] = m.Value
+		}
+
+		result.Benchmarks = append(result.Benchmarks, benchmark)
+	}
+
+	return result
+}
+
+func (j *JSONReporter) buildTest(root TestEntry, t TestEntry) jsonTest {
+	result := jsonTest{
+		Name:       t.Name,
+		Status:     t.Status(),
+		Elapsed:    t.Elapsed,
+		Panicked:   t.Panicked,
+		Unfinished: t.Unfinished,
+		Flaky:      t.Flaky,
+		FuzzInput:  t.FuzzInput,
+		Output:     t.Output,
+	}
+
+	if t.Action == "skip" {
+		result.SkipReason = t.SkipReason()
+	}
+
+	if t.Action == "fail" {
+		for _, assert := range ParseTestifyAsserts(t.Output) {
+			result.Asserts = append(result.Asserts, jsonAssert{
+				Error:    assert.ErrorText(),
+				Messages: strings.Join(assert.MessageLines(), "\n"),
+				Trace:    assert.TraceLines(),
+				Test:     assert.TestName(),
+			})
+		}
+	}
+
+	for _, st := range root.DirectSubTests(t.Name) {
+		result.SubTests = append(result.SubTests, j.buildTest(root, st))
+	}
+
+	return result
+}
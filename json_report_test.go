@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_jsonReport(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	processTestdata(t, NewProcessor(Config{JSONFile: path}), "testify_fail.txt")
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	var report jsonReport
+	r.NoError(json.Unmarshal(content, &report))
+
+	a.Equal(jsonReportVersion, report.Version)
+	a.Equal(jsonSummary{Total: 100, Passed: 98, Failed: 2, Elapsed: report.Summary.Elapsed}, report.Summary)
+
+	var pkg *jsonPackage
+	for _, p := range report.Packages {
+		if p.Name == "github.com/joaopsramos/fincon/internal/service" {
+			pkg = &p
+		}
+	}
+
+	r.NotNil(pkg)
+	a.Equal("fail", pkg.Status)
+	a.False(pkg.Cached)
+
+	var test *jsonTest
+	for _, tt := range pkg.Tests {
+		if tt.Name == "TestPostgresExpense_GetSummary" {
+			test = &tt
+		}
+	}
+
+	r.NotNil(test)
+	a.Equal("fail", test.Status)
+
+	var subTest *jsonTest
+	for _, st := range test.SubTests {
+		if st.Name == "TestPostgresExpense_GetSummary/should_handle_next_month_with_carried_over_excesses" {
+			subTest = &st
+		}
+	}
+
+	r.NotNil(subTest)
+	r.Len(subTest.Asserts, 1)
+	a.Equal(jsonAssert{
+		Error: `Not equal:
+expected: "\tone\ntwo\nthree\n\tfour"
+actual  : "\tone\nthree\ntwo\n\tfour"
+
+Diff:
+--- Expected
++++ Actual
+@@ -1,4 +1,4 @@
+	one
++three
+ two
+-three
+	four`,
+		Trace: []string{
+			"/home/joao/www/fincon/backend/internal/service/expense_test.go:97",
+			"/home/joao/www/fincon/backend/internal/service/expense_test.go:199",
+		},
+		Test: "TestPostgresExpense_GetSummary/should_handle_next_month_with_carried_over_excesses",
+	}, subTest.Asserts[0])
+}
+
+func Test_jsonReportPassedTestWithTrace(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	processTestdata(t, NewProcessor(Config{JSONFile: path}), "pass_error_trace.txt")
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	var report jsonReport
+	r.NoError(json.Unmarshal(content, &report))
+	r.Len(report.Packages, 1)
+	r.Len(report.Packages[0].Tests, 1)
+
+	test := report.Packages[0].Tests[0]
+	assert.Equal(t, "pass", test.Status)
+	assert.Empty(t, test.Asserts)
+	assert.Contains(t, test.Output, "Error Trace:")
+}
+
+func Test_jsonReportBenchmarks(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	processTestdata(t, NewProcessor(Config{JSONFile: path}), "benchmark_metrics.txt")
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	var report jsonReport
+	r.NoError(json.Unmarshal(content, &report))
+	r.Len(report.Packages, 2)
+
+	hash := report.Packages[0]
+	a.Equal("example.com/bench/hash", hash.Name)
+	a.Equal([]jsonBenchmark{
+		{Name: "BenchmarkSum256", Procs: 1, Iterations: 2000, NsPerOp: 903.5, Metrics: map[string]float64{"MB/s": 1133.32, "bytes/hash": 1024}},
+		{Name: "BenchmarkSum256", Procs: 2, Iterations: 2000, NsPerOp: 1055, Metrics: map[string]float64{"MB/s": 970.16, "bytes/hash": 1024}},
+	}, hash.Benchmarks)
+
+	strs := report.Packages[1]
+	r.Len(strs.Benchmarks, 6)
+
+	bytes, allocs := int64(3320), int64(9)
+	a.Equal(jsonBenchmark{
+		Name: "BenchmarkBuilder/large", Procs: 2, Iterations: 2000, NsPerOp: 5678, BytesPerOp: &bytes, AllocsPerOp: &allocs,
+	}, strs.Benchmarks[5])
+}
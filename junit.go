@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/joaopsramos/gotestpp/utils"
+)
+
+type JUnitReporter struct {
+	path string
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",cdata"`
+}
+
+func NewJUnitReporter(path string) *JUnitReporter {
+	return &JUnitReporter{path: path}
+}
+
+func (j *JUnitReporter) Report(run *Run) error {
+	suites := junitTestSuites{Time: junitTime(run.Summary.Elapsed)}
+
+	for _, pkg := range run.Packages {
+		suite := j.buildSuite(run, pkg)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	output, err := xml.MarshalIndent(suites, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(j.path, append([]byte(xml.Header), append(output, '\n')...), 0o644)
+}
+
+func (j *JUnitReporter) buildSuite(run *Run, pkg *PackageRun) junitTestSuite {
+	suite := junitTestSuite{Name: pkg.Name, Time: junitTime(pkg.Entry.Elapsed)}
+
+	if pkg.Entry.BuildFailed {
+		suite.Tests = 1
+		suite.Errors = 1
+		suite.TestCases = []junitTestCase{{
+			ClassName: pkg.Name,
+			Name:      "[build failed]",
+			Time:      junitTime(0),
+			Error:     &junitMessage{Message: "build failed", Body: run.BuildOutput(pkg.Name)},
+		}}
+
+		return suite
+	}
+
+	for _, t := range pkg.AllTests() {
+		testCase := junitTestCase{ClassName: pkg.Name, Name: t.Name, Time: junitTime(t.Elapsed)}
+
+		// Flaky tests passed when rerun, so they aren't failures
+		switch t.Status() {
+		case "fail":
+			suite.Failures++
+			message := "Failed"
+			if t.Unfinished {
+				message = "Did not finish"
+			}
+
+			testCase.Failure = &junitMessage{Message: message, Body: utils.StripANSI(formatOutput(t))}
+
+		case "skip":
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: t.SkipReason()}
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	return suite
+}
+
+func junitTime(elapsed float64) string {
+	return fmt.Sprintf("%.3f", elapsed)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_junitReport(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "report.xml")
+	processTestdata(t, NewProcessor(Config{JUnitFile: path}), "fail_with_skip.txt")
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	var report junitTestSuites
+	r.NoError(xml.Unmarshal(content, &report))
+
+	a.Equal(1, report.Failures)
+	a.Equal(1, report.Skipped)
+	a.Equal(0, report.Errors)
+
+	var failure, skipped *junitTestCase
+	for _, suite := range report.Suites {
+		for _, tc := range suite.TestCases {
+			switch {
+			case tc.Failure != nil:
+				failure = &tc
+			case tc.Skipped != nil:
+				skipped = &tc
+			}
+		}
+	}
+
+	r.NotNil(failure)
+	a.Equal("github.com/joaopsramos/fincon/internal/service", failure.ClassName)
+	a.Equal("TestPostgresExpense_GetSummary", failure.Name)
+	a.Equal(`	expense_test.go:201:
+	Error:
+		Not equal:
+		expected: 1
+		actual  : 2
+	Error Trace:
+		/home/joao/www/fincon/backend/internal/service/expense_test.go:201`, failure.Failure.Body)
+
+	r.NotNil(skipped)
+	a.Equal("TestExpenseService_Create", skipped.Name)
+	a.Equal("expense_test.go:205", skipped.Skipped.Message)
+}
+
+func Test_junitReportBuildFailed(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "report.xml")
+	processTestdata(t, NewProcessor(Config{JUnitFile: path}), "build_failed.txt")
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	var report junitTestSuites
+	r.NoError(xml.Unmarshal(content, &report))
+
+	a.Equal(1, report.Errors)
+
+	for _, suite := range report.Suites {
+		if suite.Name != "github.com/joaopsramos/fincon/internal/service" {
+			continue
+		}
+
+		r.Len(suite.TestCases, 1)
+		a.Equal("build failed", suite.TestCases[0].Error.Message)
+		a.Equal(`# github.com/joaopsramos/fincon/internal/service_test [github.com/joaopsramos/fincon/internal/service.test]
+internal/service/expense_test.go:203:2: undefined: pan
+`, suite.TestCases[0].Error.Body)
+	}
+}
+
+func Test_junitReportTimeout(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "report.xml")
+	processTestdata(t, NewProcessor(Config{JUnitFile: path}), "timeout.txt")
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	var report junitTestSuites
+	r.NoError(xml.Unmarshal(content, &report))
+
+	a.Equal(1, report.Failures)
+
+	testCases := report.Suites[0].TestCases
+	r.Len(testCases, 2)
+	a.Equal("TestFast", testCases[0].Name)
+	a.Nil(testCases[0].Failure)
+
+	a.Equal("TestSlow", testCases[1].Name)
+	r.NotNil(testCases[1].Failure)
+	a.Equal("Did not finish", testCases[1].Failure.Message)
+	a.Contains(testCases[1].Failure.Body, "panic: test timed out after 1s")
+	a.Equal("1.011", testCases[1].Time)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	lastRunDir  = ".gotestpp"
+	lastRunFile = "last-run.json"
+)
+
+// LastRun is the state saved after every run, so the next one can run only the tests that failed.
+type LastRun struct {
+	Failed []LastRunTest `json:"failed"`
+}
+
+// LastRunTest is a failed test, an empty Test means the whole package failed, e.g. because it didn't build.
+type LastRunTest struct {
+	Package string `json:"package"`
+	Test    string `json:"test,omitempty"`
+}
+
+func lastRunPath(module Module) string {
+	return filepath.Join(module.Dir, lastRunDir, lastRunFile)
+}
+
+func LoadLastRun(module Module) (LastRun, error) {
+	lastRun := LastRun{}
+
+	content, err := os.ReadFile(lastRunPath(module))
+	if errors.Is(err, os.ErrNotExist) {
+		return lastRun, nil
+	}
+	if err != nil {
+		return lastRun, err
+	}
+
+	err = json.Unmarshal(content, &lastRun)
+	return lastRun, err
+}
+
+// SaveLastRun stores the failures of the run. Previous failures of tests that didn't run are kept, so running
+// a single package or test, e.g. with -run, doesn't forget about the others.
+func SaveLastRun(module Module, previous LastRun, run *Run) error {
+	lastRun := LastRun{Failed: []LastRunTest{}}
+
+	for _, t := range previous.Failed {
+		if !ranInRun(run, t) {
+			lastRun.Failed = append(lastRun.Failed, t)
+		}
+	}
+
+	lastRun.Failed = append(lastRun.Failed, runFailures(run)...)
+
+	dir := filepath.Join(module.Dir, lastRunDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	// The state is local to each machine and shouldn't be committed
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*\n"), 0o644); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(lastRun, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(lastRunPath(module), append(content, '\n'), 0o644)
+}
+
+// ranInRun reports whether the run ran the failed test again, either the test itself or its parent test.
+// Failures of a whole package are covered by any run of the package.
+func ranInRun(run *Run, failed LastRunTest) bool {
+	pkg, ok := run.pkgs[failed.Package]
+	if !ok {
+		return false
+	}
+
+	if failed.Test == "" {
+		return true
+	}
+
+	for _, t := range pkg.AllTests() {
+		if t.Name == failed.Test || strings.HasPrefix(failed.Test, t.Name+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runFailures returns the failed tests of the run that are not flaky, using the same names used to rerun them.
+func runFailures(run *Run) []LastRunTest {
+	failures := []LastRunTest{}
+
+	for _, pkg := range run.Packages {
+		failedTests := pkg.FailedTests()
+
+		if pkg.Entry.BuildFailed || (pkg.Failed() && len(failedTests) == 0) {
+			failures = append(failures, LastRunTest{Package: pkg.Name})
+			continue
+		}
+
+		for _, t := range failedTests {
+			for _, name := range rerunNames(t, t) {
+				flaky := slices.ContainsFunc(run.Flaky, func(f FlakyTest) bool {
+					return f.Pkg == pkg.Name && f.Name == name
+				})
+
+				if !flaky {
+					failures = append(failures, LastRunTest{Package: pkg.Name, Test: name})
+				}
+			}
+		}
+	}
+
+	return failures
+}
+
+// Args builds the go test args to run only the failed tests. Packages that failed as a whole
+// are run entirely, and since -run applies to every package, so are the others.
+func (l LastRun) Args(flags []string) []string {
+	pkgs := []string{}
+	names := []string{}
+	wholePkg := false
+
+	for _, t := range l.Failed {
+		if !slices.Contains(pkgs, t.Package) {
+			pkgs = append(pkgs, t.Package)
+		}
+
+		if t.Test == "" {
+			wholePkg = true
+		} else if !slices.Contains(names, t.Test) {
+			names = append(names, t.Test)
+		}
+	}
+
+	if wholePkg {
+		return goTestArgs(pkgs, flags)
+	}
+
+	return goTestArgs(pkgs, flags, "-run="+runPattern(names))
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveLastRun(t *testing.T) {
+	a := assert.New(t)
+	module := Module{Path: "github.com/joaopsramos/fincon", Dir: t.TempDir()}
+
+	processor := NewProcessor(Config{})
+	processTestdata(t, processor, "subtest_fail.txt")
+
+	previous := LastRun{Failed: []LastRunTest{
+		{Package: "github.com/joaopsramos/fincon/internal/other", Test: "TestOther"},
+		{Package: "github.com/joaopsramos/fincon/internal/api", Test: "TestApi_CreateUser/invalid_email"},
+		{Package: "github.com/joaopsramos/fincon/internal/api", Test: "TestApi_CreateUser/removed_subtest"},
+		{Package: "github.com/joaopsramos/fincon/internal/api", Test: "TestApi_Removed"},
+	}}
+
+	err := SaveLastRun(module, previous, processor.renderer.Run())
+	a.NoError(err)
+
+	lastRun, err := LoadLastRun(module)
+	a.NoError(err)
+	a.Equal([]LastRunTest{
+		{Package: "github.com/joaopsramos/fincon/internal/other", Test: "TestOther"},
+		{Package: "github.com/joaopsramos/fincon/internal/api", Test: "TestApi_Removed"},
+		{Package: "github.com/joaopsramos/fincon/internal/service", Test: "TestExpenseService_Create/handle_float_precision_edge_cases"},
+	}, lastRun.Failed)
+
+	gitignore, err := os.ReadFile(filepath.Join(module.Dir, ".gotestpp", ".gitignore"))
+	a.NoError(err)
+	a.Equal("*\n", string(gitignore))
+}
+
+func TestSaveLastRun_partialRun(t *testing.T) {
+	a := assert.New(t)
+	module := Module{Path: "example.com", Dir: t.TempDir()}
+
+	previous := LastRun{Failed: []LastRunTest{
+		{Package: "example.com/b", Test: "TestFail"},
+		{Package: "example.com/b", Test: "TestSlow"},
+		{Package: "example.com/c"},
+	}}
+
+	// gotestpp -run TestSlow ./b ./c
+	run := NewRun()
+	b := run.Package("example.com/b")
+	b.Entry = TestEntry{Pkg: "example.com/b", Action: "pass"}
+	b.Tests = []TestEntry{{Pkg: "example.com/b", Name: "TestSlow", Action: "pass"}}
+	run.Package("example.com/c").Entry = TestEntry{Pkg: "example.com/c", Action: "pass"}
+
+	a.NoError(SaveLastRun(module, previous, run))
+
+	lastRun, err := LoadLastRun(module)
+	a.NoError(err)
+	a.Equal([]LastRunTest{{Package: "example.com/b", Test: "TestFail"}}, lastRun.Failed)
+}
+
+func TestLoadLastRun_missing(t *testing.T) {
+	lastRun, err := LoadLastRun(Module{Dir: t.TempDir()})
+	assert.NoError(t, err)
+	assert.Empty(t, lastRun.Failed)
+}
+
+func TestLastRun_Args(t *testing.T) {
+	lastRun := LastRun{Failed: []LastRunTest{
+		{Package: "example.com/a", Test: "TestA/sub"},
+		{Package: "example.com/b", Test: "TestB"},
+		{Package: "example.com/a", Test: "TestC"},
+	}}
+
+	args := lastRun.Args([]string{"-v", "-run", "TestX"})
+	assert.Equal(t, []string{"example.com/a", "example.com/b", "-v", "-run=^TestA$/^sub$|^TestB$|^TestC$"}, args)
+
+	lastRun.Failed = append(lastRun.Failed, LastRunTest{Package: "example.com/c"})
+	args = lastRun.Args([]string{"-v"})
+	assert.Equal(t, []string{"example.com/a", "example.com/b", "example.com/c", "-v"}, args)
+}
+
+func TestSaveLastRun_stdin(t *testing.T) {
+	dir := runFromStdin(t, Config{}, "fail.txt")
+
+	assert.NoFileExists(t, filepath.Join(dir, lastRunDir, lastRunFile))
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type LocationKind string
+
+const (
+	AssertLocation  LocationKind = "assert"
+	PanicLocation   LocationKind = "panic"
+	TimeoutLocation LocationKind = "timeout"
+	BuildLocation   LocationKind = "build"
+)
+
+var buildFileRe = regexp.MustCompile(`^(\S+\.go:\d+)(?::\d+)?: (.*)`)
+
+type Location struct {
+	Kind    LocationKind
+	File    string
+	Line    int
+	Message string
+}
+
+// TestLocations finds where a failed test reported its errors, using the t.Error file:line prefix,
+// the testify Error Trace and, for panics, the first stack frame that belongs to the test package.
+func TestLocations(t TestEntry) []Location {
+	locations := []Location{}
+	scanner := NewRewindScanner(bufio.NewScanner(strings.NewReader(t.Output)))
+	current := -1
+	panicMessage := ""
+	panicKind := PanicLocation
+	panicFound := false
+	pkgFrame := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			continue
+
+		case IsTestifyAssert(line):
+			assert := NewTestifyAssert(scanner.Text(), scanner)
+			location := Location{Kind: AssertLocation, Message: assertMessage(assert)}
+
+			if trace := assert.TraceLines(); len(trace) > 0 {
+				location.File, location.Line = splitFileLine(trace[0])
+			}
+
+			locations = append(locations, location)
+			current = -1
+
+		case strings.HasPrefix(line, "panic:"):
+			if panicMessage == "" {
+				panicMessage = line
+			}
+
+			if strings.HasPrefix(line, "panic: test timed out") {
+				panicKind = TimeoutLocation
+			}
+
+			current = -1
+
+		case panicMessage != "":
+			if pkgFrame && !panicFound && panicFileRe.MatchString(line) {
+				file, lineNumber := splitFileLine(panicFileRe.FindString(line))
+				locations = append(locations, Location{Kind: panicKind, File: file, Line: lineNumber, Message: panicMessage})
+				panicFound = true
+			}
+
+			pkgFrame = strings.HasPrefix(line, t.Pkg+".") || strings.HasPrefix(line, t.Pkg+"_test.")
+
+		default:
+			if matches := errorFileRe.FindStringSubmatch(line); len(matches) > 0 {
+				file, lineNumber := splitFileLine(strings.TrimSuffix(matches[1], ":"))
+				message := strings.TrimSpace(matches[2])
+				locations = append(locations, Location{Kind: AssertLocation, File: file, Line: lineNumber, Message: message})
+				current = len(locations) - 1
+				continue
+			}
+
+			if current >= 0 {
+				locations[current].Message = strings.TrimSpace(locations[current].Message + "\n" + line)
+			}
+		}
+	}
+
+	if panicMessage != "" && !panicFound {
+		locations = append(locations, Location{Kind: panicKind, Message: panicMessage})
+	}
+
+	// A file:line with no message is the header testify prints before the assertion
+	result := []Location{}
+	for _, location := range locations {
+		if location.Message != "" {
+			result = append(result, location)
+		}
+	}
+
+	return result
+}
+
+func BuildLocations(output string) []Location {
+	locations := []Location{}
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := buildFileRe.FindStringSubmatch(strings.TrimSpace(line))
+		if len(matches) == 0 {
+			continue
+		}
+
+		file, lineNumber := splitFileLine(matches[1])
+		locations = append(locations, Location{Kind: BuildLocation, File: file, Line: lineNumber, Message: matches[2]})
+	}
+
+	return locations
+}
+
+func assertMessage(assert TestifyAssert) string {
+	message := assert.ErrorText()
+	if lines := assert.MessageLines(); len(lines) > 0 {
+		message += "\n" + strings.Join(lines, "\n")
+	}
+
+	return message
+}
+
+func splitFileLine(s string) (string, int) {
+	file, lineNumber, ok := cutLast(s, ":")
+	if !ok {
+		return s, 0
+	}
+
+	n, err := strconv.Atoi(lineNumber)
+	if err != nil {
+		return s, 0
+	}
+
+	return file, n
+}
+
+func cutLast(s string, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+
+	return s[:i], s[i+len(sep):], true
+}
+
+// PathResolver maps the file names found in the test output to paths relative to the workspace
+// (the repository root), which is what CI tools expect.
+type PathResolver struct {
+	module    Module
+	workspace string
+	cwd       string
+}
+
+func NewPathResolver() *PathResolver {
+	cwd, _ := os.Getwd()
+	module, _ := FindModule(cwd)
+
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace == "" {
+		workspace = cwd
+		if root, ok := findUp(cwd, ".git"); ok {
+			workspace = root
+		}
+	}
+
+	return &PathResolver{module: module, workspace: workspace, cwd: cwd}
+}
+
+func (r *PathResolver) Resolve(pkg string, file string) (string, bool) {
+	if file == "" {
+		return "", false
+	}
+
+	pkgDir, pkgOk := r.module.PackageDir(pkg)
+
+	var abs string
+	switch {
+	case filepath.IsAbs(file):
+		abs = file
+
+	case !strings.ContainsAny(file, `/\`):
+		// t.Error and friends only print the file name, which is relative to the package
+		if !pkgOk {
+			return "", false
+		}
+		abs = filepath.Join(pkgDir, file)
+
+	default:
+		// Build errors are relative to the directory where go test was run
+		abs = filepath.Join(r.cwd, file)
+	}
+
+	rel, ok := r.relToWorkspace(abs)
+	if ok {
+		return rel, true
+	}
+
+	// The output may come from another machine, so we use the package to find where the file is
+	relPkg, relOk := r.module.RelPackage(pkg)
+	if pkgOk && relOk && strings.HasSuffix(filepath.ToSlash(filepath.Dir(abs)), "/"+relPkg) {
+		return r.relToWorkspace(filepath.Join(pkgDir, filepath.Base(abs)))
+	}
+
+	return "", false
+}
+
+func (r *PathResolver) relToWorkspace(abs string) (string, bool) {
+	rel, err := filepath.Rel(r.workspace, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
@@ -6,12 +6,15 @@ import (
 )
 
 type TestEvent struct {
-	Time    time.Time
-	Action  string
-	Pkg     string `json:"Package"`
-	Name    string `json:"Test"`
-	Output  string
-	Elapsed float64
+	Time        time.Time
+	Action      string
+	Pkg         string `json:"Package"`
+	Name        string `json:"Test"`
+	Output      string
+	OutputType  string
+	Elapsed     float64
+	ImportPath  string
+	FailedBuild string
 }
 
 func (l TestEvent) buildID() string {
@@ -1,11 +1,40 @@
 package main
 
 import (
+	"fmt"
 	"os"
+
+	"github.com/fatih/color"
 )
 
 func main() {
-	processor := NewProcessor()
+	args := os.Args[1:]
+
+	command := ""
+	if len(args) > 0 && (args[0] == "watch" || args[0] == "cover") {
+		command, args = args[0], args[1:]
+	}
+
+	config, err := ParseConfig(args)
+	if err != nil {
+		os.Exit(2)
+	}
+
+	if command == "cover" {
+		os.Exit(NewProcessor(config).Cover())
+	}
+
+	if command == "watch" {
+		watcher, err := NewWatcher(config)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, color.RedString("failed to start watch mode: %s", err))
+			os.Exit(1)
+		}
+
+		os.Exit(watcher.Run())
+	}
+
+	processor := NewProcessor(config)
 	result := processor.Run()
 
 	os.Exit(result)
@@ -6,10 +6,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_process(t *testing.T) {
@@ -38,12 +40,22 @@ func Test_process(t *testing.T) {
 		{"multiple failures", "multiple_fails.txt", multipleFailsOutput},
 		{"panic", "panic.txt", panicOutput},
 		{"panic after assert", "panic_after_assert.txt", panicAfterAssertOutput},
-		{"unexpected outputs", "benchmark.txt", benchmarkOutput},
+		{"benchmark", "benchmark.txt", benchmarkOutput},
+		{"benchmark metrics", "benchmark_metrics.txt", benchmarkMetricsOutput},
+		{"benchmark like output", "benchmark_like_output.txt", benchmarkLikeOutput},
+		{"did not finish", "did_not_finish.txt", didNotFinishOutput},
+		{"parallel", "parallel.txt", parallelOutput},
+		{"fuzz", "fuzz.txt", fuzzOutput},
+		{"fuzz corpus", "fuzz_corpus.txt", fuzzCorpusOutput},
+		{"fuzz like output", "fuzz_like_output.txt", fuzzLikeOutput},
+		{"parallel from verbose output", "parallel_verbose.txt", parallelVerboseOutput},
+		{"coverage", "coverage.txt", coverageOutput},
+		{"build failed in a dependency", "build_failed_deps.txt", buildFailedDepsOutput},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := assert.New(t)
-			processor := NewProcessor()
+			processor := NewProcessor(Config{})
 
 			file, err := os.Open(filepath.Join("testdata", tt.fileName))
 			a.NoError(err)
@@ -58,6 +70,68 @@ func Test_process(t *testing.T) {
 	}
 }
 
+func Test_process_stream(t *testing.T) {
+	a := assert.New(t)
+
+	processor := NewProcessor(Config{Stream: true})
+	output := processTestdata(t, processor, "fail.txt")
+
+	recap := strings.Index(output, "Recap:")
+	a.Greater(recap, 0)
+
+	failure := "--- FAIL TestExpenseService_Create"
+	a.Equal(2, strings.Count(output, failure))
+	a.Less(strings.Index(output, failure), recap)
+	a.Greater(strings.LastIndex(output, failure), recap)
+
+	// Without a terminal there is no status line
+	a.NotContains(output, "running")
+}
+
+func Test_process_failFast(t *testing.T) {
+	a := assert.New(t)
+
+	aborts := 0
+	processor := NewProcessor(Config{})
+	processor.renderer.EnableFailFast(func() { aborts++ })
+
+	output := processTestdata(t, processor, "aborted.txt")
+
+	a.Equal(1, aborts)
+	a.Equal(`ABORTED	example.com/ff/a
+
+--- FAIL TestFails (0.00s)
+	a_test.go:3: boom
+
+Finished in 0.00s
+1 tests, 1 failed
+1 packages aborted
+`, output)
+}
+
+func Test_process_buildFailedDeps(t *testing.T) {
+	a := assert.New(t)
+
+	file, err := os.Open(filepath.Join("testdata", "build_failed_deps.txt"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	processor := NewProcessor(Config{})
+	code := 0
+	captureOutput(func() {
+		code = processor.Process(file)
+	})
+
+	a.Equal(1, code)
+
+	// The build output is kept out of the errors, but each package still has it for the reports
+	run := processor.renderer.Run()
+	a.Equal([]string{"go: warning: ignoring go.work"}, run.Errors)
+	for _, pkg := range []string{"example.com/bf/a", "example.com/bf/lib"} {
+		a.Equal("# example.com/bf/lib\nlib/lib.go:4:9: undefined: undefinedValue\n", run.BuildOutput(pkg))
+	}
+}
+
 var (
 	successOutput = `?	github.com/joaopsramos/fincon/cmd/fincon	[no test files]
 ?	github.com/joaopsramos/fincon/cmd/migrate_db	[no test files]
@@ -391,8 +465,12 @@ testing.tRunner(0xc0001c1d40, 0xce9d30)
 created by testing.(*T).Run in goroutine 1
 	/home/joao/.asdf/installs/golang/1.23.4/go/src/testing/testing.go:1743 +0x390
 
+--- DID NOT FINISH TestExpenseService_Create (running, ran for 0.01s)
+
+--- DID NOT FINISH TestExpenseService_UpdateByID (running, ran for 0.01s)
+
 Finished in 0.01s
-95 tests, 1 failed
+97 tests, 3 failed
 `
 
 	panicAfterAssertOutput = `?	github.com/joaopsramos/fincon/cmd/fincon	[no test files]
@@ -426,8 +504,13 @@ testing.tRunner(0xc0001c1d40, 0xce9d50)
 created by testing.(*T).Run in goroutine 1
 	/home/joao/.asdf/installs/golang/1.23.4/go/src/testing/testing.go:1743 +0x390
 
+--- DID NOT FINISH TestExpenseService_Create (running, ran for 0.01s)
+--- DID NOT FINISH TestExpenseService_Create/handle_float_precision_edge_cases (running, ran for 0.00s)
+
+--- DID NOT FINISH TestExpenseService_UpdateByID (running, ran for 0.01s)
+
 Finished in 0.01s
-95 tests, 1 failed
+98 tests, 4 failed
 `
 
 	benchmarkOutput = `?	github.com/joaopsramos/fincon/cmd/fincon	[no test files]
@@ -442,15 +525,257 @@ ok	github.com/joaopsramos/fincon/internal/service	0.02s
 ?	github.com/joaopsramos/fincon/internal/testhelper	[no test files]
 ?	github.com/joaopsramos/fincon/internal/util	[no test files]
 
-Unparsed:
-BenchmarkHello
-BenchmarkHello-12    	1000000000	         0.2345 ns/op
+Benchmarks:
+
+github.com/joaopsramos/fincon/internal/api
+	BenchmarkHello-12  1000000000  0.2345 ns/op
 
 Finished in 0.85s
 103 tests
+`
+
+	benchmarkMetricsOutput = `ok	example.com/bench/hash	0.02s
+ok	example.com/bench/strs	0.07s
+
+Benchmarks:
+
+example.com/bench/hash
+	BenchmarkSum256    2000  903.5 ns/op  1133.32 MB/s  1024 bytes/hash
+	BenchmarkSum256-2  2000   1055 ns/op   970.16 MB/s  1024 bytes/hash
+
+example.com/bench/strs
+	BenchmarkJoin             2000  79.87 ns/op
+	BenchmarkJoin-2           2000  89.13 ns/op
+	BenchmarkBuilder/small    2000  117.9 ns/op    24 B/op  2 allocs/op
+	BenchmarkBuilder/small-2  2000  121.4 ns/op    24 B/op  2 allocs/op
+	BenchmarkBuilder/large    2000   5576 ns/op  3320 B/op  9 allocs/op
+	BenchmarkBuilder/large-2  2000   5678 ns/op  3320 B/op  9 allocs/op
+
+Finished in 0.09s
+0 tests
+`
+
+	benchmarkLikeOutput = `FAIL	example.com/bo/setup
+
+--- FAIL TestSetup (0.00s)
+BenchmarkSetup
+Benchmarks skipped
+
+--- FAIL TestOther (0.00s)
+other output
+
+Finished in 0.00s
+2 tests, 2 failed
+`
+
+	didNotFinishOutput = `FAIL	example.com/dnf/exit
+
+--- DID NOT FINISH TestParallel (paused, ran for 0.00s)
+
+--- DID NOT FINISH TestExits (running, ran for 0.05s)
+--- DID NOT FINISH TestExits/calls_os.Exit (running, ran for 0.05s)
+	exit_test.go:19: about to exit
+
+Finished in 0.05s
+5 tests, 3 failed
+`
+
+	fuzzOutput = `FAIL	example.com/fuzz/parse
+
+--- FAIL FuzzReverse (4.92s)
+	parse_test.go:10: Reverse("FUZ0"): unsupported prefix
+	Fuzzing: elapsed: 3s, execs: 130623 (43529/sec), new interesting: 9 (total: 10)
+	Failing input: testdata/fuzz/FuzzReverse/c90c653d73cb07c7
+	To reproduce: go test -run=FuzzReverse/c90c653d73cb07c7 example.com/fuzz/parse
+
+Finished in 4.92s
+1 tests, 1 failed
+`
+
+	fuzzCorpusOutput = `FAIL	example.com/fuzz/parse
+
+--- FAIL FuzzReverse (0.00s)
+--- FAIL FuzzReverse/c90c653d73cb07c7 (0.00s)
+	parse_test.go:10: Reverse("FUZ0"): unsupported prefix
+	Failing input: testdata/fuzz/FuzzReverse/c90c653d73cb07c7
+	To reproduce: go test -run=FuzzReverse/c90c653d73cb07c7 example.com/fuzz/parse
+
+Finished in 0.01s
+3 tests, 2 failed
+`
+
+	fuzzLikeOutput = `FAIL	example.com/fz/log
+
+--- FAIL TestLog (0.00s)
+fuzz: not a fuzz target
+	log_test.go:10: boom
+
+Finished in 0.00s
+1 tests, 1 failed
+`
+
+	parallelOutput = `ok	example.com/par/b	0.00s
+FAIL	example.com/par/a
+
+--- FAIL TestSubtests (0.00s)
+	a_test.go:45: parent: after run
+
+--- FAIL TestSubtests/first (0.01s)
+	a_test.go:37: first: start
+	a_test.go:40: first: failed
+	a_test.go:42: first: end
+
+--- FAIL TestSubtests/third (0.01s)
+	a_test.go:37: third: start
+	a_test.go:40: third: failed
+	a_test.go:42: third: end
+
+--- FAIL TestParallelOne (0.04s)
+	a_test.go:10: one: start
+	a_test.go:12: one: middle
+	a_test.go:14: one: failed
+
+--- FAIL TestParallelTwo (0.05s)
+	a_test.go:20: two: start
+	a_test.go:22: two: middle
+	a_test.go:24: two: failed
+
+Finished in 0.07s
+9 tests, 5 failed
+`
+
+	parallelVerboseOutput = `FAIL	example.com/par/a
+
+--- FAIL TestSubtests (0.00s)
+	a_test.go:45: parent: after run
+
+--- FAIL TestSubtests/first (0.01s)
+	a_test.go:37: first: start
+	a_test.go:40: first: failed
+	a_test.go:42: first: end
+
+--- FAIL TestSubtests/third (0.01s)
+	a_test.go:37: third: start
+	a_test.go:40: third: failed
+	a_test.go:42: third: end
+
+--- FAIL TestParallelOne (0.04s)
+	a_test.go:10: one: start
+	a_test.go:12: one: middle
+	a_test.go:14: one: failed
+
+--- FAIL TestParallelTwo (0.05s)
+	a_test.go:20: two: start
+	a_test.go:22: two: middle
+	a_test.go:24: two: failed
+
+Finished in 0.00s
+7 tests, 5 failed
+`
+
+	buildFailedDepsOutput = `FAIL	example.com/bf/a	[build failed]
+FAIL	example.com/bf/b	[build failed]
+FAIL	example.com/bf/c	[build failed]
+FAIL	example.com/bf/lib	[build failed]
+
+Build errors:
+# example.com/bf/lib
+lib/lib.go:4:9: undefined: undefinedValue
+
+Errors:
+go: warning: ignoring go.work
+
+Finished in 0.00s
+0 tests
+`
+
+	coverageOutput = `ok	example.com/cov/a	0.01s	coverage: 25.0%
+ok	example.com/cov/b	0.00s	coverage: 100.0%
+?	example.com/cov/c	[no test files]	coverage: 0.0%
+ok	example.com/cov/d	0.00s
+
+Finished in 0.01s
+3 tests
 `
 )
 
+func processTestdata(t *testing.T, processor *Processor, fileName string) string {
+	t.Helper()
+	color.NoColor = true
+
+	originalStdout := os.Stdout
+	t.Cleanup(func() {
+		os.Stdout = originalStdout
+	})
+
+	file, err := os.Open(filepath.Join("testdata", fileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	return captureOutput(func() {
+		processor.Process(file)
+	})
+}
+
+// runFromStdin pipes the test data into Processor.Run from a module in a temporary directory, which it returns.
+func runFromStdin(t *testing.T, config Config, fileName string) string {
+	t.Helper()
+	color.NoColor = true
+
+	content, err := os.ReadFile(filepath.Join("testdata", fileName))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/joaopsramos/fincon\n"), 0o644))
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	originalStdin, originalStdout := os.Stdin, os.Stdout
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Chdir(cwd)
+		os.Stdin, os.Stdout = originalStdin, originalStdout
+		r.Close()
+	})
+
+	go func() {
+		w.Write(content)
+		w.Close()
+	}()
+
+	captureStderr(func() {
+		captureOutput(func() {
+			NewProcessor(config).Run()
+		})
+	})
+
+	return dir
+}
+
+func captureStderr(fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stderr := os.Stderr
+	os.Stderr = w
+	fn()
+	w.Close()
+	os.Stderr = stderr
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
 func captureOutput(fn func()) string {
 	r, w, err := os.Pipe()
 	if err != nil {
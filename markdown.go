@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/joaopsramos/gotestpp/utils"
+)
+
+var backticksRe = regexp.MustCompile("`+")
+
+type MarkdownReporter struct {
+	path string
+}
+
+func NewMarkdownReporter(path string) *MarkdownReporter {
+	return &MarkdownReporter{path: path}
+}
+
+func (m *MarkdownReporter) Report(run *Run) error {
+	var b strings.Builder
+
+	b.WriteString("## Test results\n\n")
+	m.writePackages(&b, run)
+	m.writeFailures(&b, run)
+	m.writeSkipped(&b, run)
+	m.writeErrors(&b, run)
+
+	summary := strings.ReplaceAll(utils.StripANSI(run.Summary.String()), "\n", "<br>\n")
+	fmt.Fprintf(&b, "\n%s\n", summary)
+
+	// The file is appended to, like $GITHUB_STEP_SUMMARY expects, so the summaries of earlier steps are kept
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if stat, err := file.Stat(); err == nil && stat.Size() > 0 {
+		file.WriteString("\n")
+	}
+
+	if _, err := file.WriteString(b.String()); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func (m *MarkdownReporter) writePackages(b *strings.Builder, run *Run) {
+	b.WriteString("| Package | Status | Elapsed |\n| --- | --- | --- |\n")
+
+	for _, pkg := range run.Packages {
+		status := ""
+		elapsed := fmt.Sprintf("%.2fs", pkg.Entry.Elapsed)
+
+		switch {
+		case pkg.Entry.BuildFailed:
+			status = ":x: build failed"
+		case pkg.Status() == "fail":
+			status = ":x: FAIL"
+		case pkg.Flaky():
+			status = ":warning: flaky"
+		case pkg.Entry.NoTestFiles:
+			status = ":grey_question: no test files"
+			elapsed = ""
+		case pkg.Entry.Cached:
+			status = ":white_check_mark: ok"
+			elapsed = "(cached)"
+		default:
+			status = ":white_check_mark: ok"
+		}
+
+		fmt.Fprintf(b, "| `%s` | %s | %s |\n", pkg.Name, status, elapsed)
+	}
+}
+
+func (m *MarkdownReporter) writeFailures(b *strings.Builder, run *Run) {
+	header := false
+
+	for _, pkg := range run.Packages {
+		for _, t := range pkg.Tests {
+			if t.Status() != "fail" {
+				continue
+			}
+
+			if !header {
+				b.WriteString("\n### Failed tests\n")
+				header = true
+			}
+
+			fmt.Fprintf(b, "\n<details>\n<summary><code>%s</code> %s (%.2fs)</summary>\n\n", t.Name, pkg.Name, t.Elapsed)
+			b.WriteString(markdownCodeBlock(utils.StripANSI(formatError(t))))
+			b.WriteString("\n</details>\n")
+		}
+	}
+}
+
+func (m *MarkdownReporter) writeSkipped(b *strings.Builder, run *Run) {
+	header := false
+
+	for _, pkg := range run.Packages {
+		for _, t := range pkg.AllTests() {
+			if t.Action != "skip" {
+				continue
+			}
+
+			if !header {
+				b.WriteString("\n### Skipped tests\n\n")
+				header = true
+			}
+
+			fmt.Fprintf(b, "- `%s` (%.2fs)", t.Name, t.Elapsed)
+			if reason := t.SkipReason(); reason != "" {
+				fmt.Fprintf(b, ": %s", strings.ReplaceAll(reason, "\n", " "))
+			}
+			b.WriteString("\n")
+		}
+	}
+}
+
+func (m *MarkdownReporter) writeErrors(b *strings.Builder, run *Run) {
+	if len(run.Errors) == 0 {
+		return
+	}
+
+	b.WriteString("\n### Errors\n\n")
+	b.WriteString(markdownCodeBlock(strings.Join(run.Errors, "\n")))
+}
+
+// markdownCodeBlock wraps s in a fence longer than any run of backticks inside it.
+func markdownCodeBlock(s string) string {
+	fence := "```"
+	for _, match := range backticksRe.FindAllString(s, -1) {
+		if len(match) >= len(fence) {
+			fence = strings.Repeat("`", len(match)+1)
+		}
+	}
+
+	return fmt.Sprintf("%s\n%s\n%s\n", fence, strings.TrimSuffix(s, "\n"), fence)
+}
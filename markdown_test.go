@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_markdownReport(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	// Like $GITHUB_STEP_SUMMARY, the file may have the summaries of earlier steps
+	path := filepath.Join(t.TempDir(), "summary.md")
+	r.NoError(os.WriteFile(path, []byte("## Lint\n\nNo issues\n"), 0o644))
+
+	processTestdata(t, NewProcessor(Config{MarkdownFile: path}), "fail_with_skip.txt")
+
+	content, err := os.ReadFile(path)
+	r.NoError(err)
+
+	output := string(content)
+	a.True(strings.HasPrefix(output, "## Lint\n\nNo issues\n\n## Test results\n\n"))
+	a.Contains(output, "| `github.com/joaopsramos/fincon/internal/api` | :white_check_mark: ok | (cached) |\n")
+	a.Contains(output, "| `github.com/joaopsramos/fincon/internal/service` | :x: FAIL | 0.02s |\n")
+	a.Contains(output, "<summary><code>TestPostgresExpense_GetSummary</code> github.com/joaopsramos/fincon/internal/service (0.01s)</summary>\n\n```\n--- FAIL TestPostgresExpense_GetSummary (0.01s)\n\texpense_test.go:201:\n")
+	a.Contains(output, "- `TestExpenseService_Create` (0.00s): expense_test.go:205\n")
+	a.Contains(output, "Finished in 0.02s<br>\n102 tests, 1 failed, 1 skipped\n")
+}
+
+func Test_markdownCodeBlock(t *testing.T) {
+	assert.Equal(t, "````\nuse ``` to start a block\n````\n", markdownCodeBlock("use ``` to start a block"))
+}
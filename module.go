@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrModuleNotFound = errors.New("go.mod not found")
+
+type Module struct {
+	Path string
+	Dir  string
+}
+
+func FindModule(dir string) (Module, error) {
+	root, ok := findUp(dir, "go.mod")
+	if !ok {
+		return Module{}, ErrModuleNotFound
+	}
+
+	file, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return Module{}, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return Module{Path: strings.Trim(strings.TrimSpace(path), `"`), Dir: root}, nil
+		}
+	}
+
+	return Module{}, ErrModuleNotFound
+}
+
+// RelPackage returns the path of the package relative to the module root, using "/" as separator.
+func (m Module) RelPackage(pkg string) (string, bool) {
+	if pkg == m.Path {
+		return ".", true
+	}
+
+	rel, ok := strings.CutPrefix(pkg, m.Path+"/")
+	return rel, ok
+}
+
+func (m Module) PackageDir(pkg string) (string, bool) {
+	rel, ok := m.RelPackage(pkg)
+	if !ok {
+		return "", false
+	}
+
+	return filepath.Join(m.Dir, filepath.FromSlash(rel)), true
+}
+
+// findUp looks for a file or directory with the given name in dir and its parents,
+// returning the directory where it was found.
+func findUp(dir string, name string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return dir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+
+		dir = parent
+	}
+}
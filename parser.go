@@ -7,6 +7,7 @@ import (
 	"io"
 	"slices"
 	"strings"
+	"time"
 )
 
 const test2jsonOutBuffer = 1024
@@ -14,8 +15,15 @@ const test2jsonOutBuffer = 1024
 var actionsToIgnore = []string{"run", "start", "pause", "cont"}
 
 type Parser struct {
-	testsMap    map[string]*TestEntry
-	subTestsMap map[string][]*TestEntry
+	testsMap     map[string]*TestEntry
+	subTestsMap  map[string][]*TestEntry
+	buildOutputs map[string]string
+	resumedAt    map[string]time.Time
+	ranFor       map[string]time.Duration
+	finishedPkgs map[string]bool
+	benchmarks   map[string][]Benchmark
+	partialLines map[string]string
+	lastTime     time.Time
 }
 
 func (p *Parser) Parse(r io.Reader, testsChan chan<- TestEntry, errsChan chan<- error) {
@@ -29,17 +37,46 @@ func (p *Parser) Parse(r io.Reader, testsChan chan<- TestEntry, errsChan chan<-
 			continue
 		}
 
-		if slices.Contains(actionsToIgnore, event.Action) {
+		switch event.Action {
+		case "build-output":
+			p.buildOutputs[event.ImportPath] += event.Output
+			continue
+
+		case "build-fail":
 			continue
 		}
 
+		if event.Time.After(p.lastTime) {
+			p.lastTime = event.Time
+		}
+
 		eventID := event.buildID()
 		test, ok := p.testsMap[eventID]
 		if !ok {
-			test = &TestEntry{Name: event.Name, Pkg: event.Pkg, EventID: eventID}
+			test = &TestEntry{Name: event.Name, Pkg: event.Pkg, EventID: eventID, Start: event.Time}
 			p.testsMap[eventID] = test
 		}
 
+		switch event.Action {
+		case "run", "cont":
+			p.resumedAt[eventID] = event.Time
+			test.Paused = false
+
+		case "pause":
+			p.ranFor[eventID] = p.runningTime(eventID, event.Time)
+			delete(p.resumedAt, eventID)
+			test.Paused = true
+		}
+
+		// The renderer uses it to know which packages are running
+		if event.Action == "start" && test.IsPkg() {
+			testsChan <- TestEntry{Pkg: test.Pkg, EventID: test.EventID, Start: test.Start, Action: event.Action}
+		}
+
+		if slices.Contains(actionsToIgnore, event.Action) {
+			continue
+		}
+
 		test.Elapsed += event.Elapsed
 
 		if event.Action != "output" {
@@ -48,14 +85,33 @@ func (p *Parser) Parse(r io.Reader, testsChan chan<- TestEntry, errsChan chan<-
 
 		switch event.Action {
 		case "pass", "skip", "fail":
+			test.End = event.Time
+
 			if test.IsPkg() {
 				test.PkgFinished = true
+				test.Benchmarks = p.benchmarks[test.Pkg]
+				p.finishedPkgs[test.Pkg] = true
 			}
 
 			test.PkgHasErrors = event.Action == "fail"
 
+			// Benchmarks have no pass event, so tests without one only mean something when the package failed
+			if test.IsPkg() && event.Action == "fail" {
+				p.finishRunning(test.Pkg, event.Time, testsChan)
+			}
+
+			if event.Action == "fail" && test.IsSubTest() {
+				test.FuzzInput = fuzzCorpusInput(test.Name)
+			}
+
+			if event.FailedBuild != "" {
+				test.BuildFailed = true
+				test.FailedBuild = event.FailedBuild
+				test.Output = p.buildOutputs[event.FailedBuild]
+			}
+
 			if test.IsSubTest() {
-				key := test.RootTestName()
+				key := subTestsKey(test)
 				p.subTestsMap[key] = append(p.subTestsMap[key], test)
 				continue
 			}
@@ -67,8 +123,28 @@ func (p *Parser) Parse(r io.Reader, testsChan chan<- TestEntry, errsChan chan<-
 				test.Panicked = true
 			}
 
+			output, isBenchmark := p.benchmarkOutput(event)
+			if isBenchmark {
+				continue
+			}
+			event.Output = output
+
+			if coverage, ok := parseCoverage(event.Output); ok && test.IsPkg() {
+				test.Coverage = &coverage
+			}
+
 			switch {
-			case p.ignoreOutput(event.Output):
+			case p.ignoreOutput(event):
+				continue
+
+			// With -cover, packages without tests print only their coverage, e.g. "\tpkg\t\tcoverage: 0.0% of statements"
+			case test.IsPkg() && strings.HasPrefix(event.Output, "\t"+test.Pkg+"\t"):
+				test.NoTestFiles = true
+
+			case test.IsPkg() && strings.HasPrefix(event.Output, "coverage:"):
+				continue
+
+			case p.fuzzOutput(test, event.Output, testsChan):
 				continue
 
 			case strings.HasPrefix(event.Output, "?"):
@@ -89,6 +165,19 @@ func (p *Parser) Parse(r io.Reader, testsChan chan<- TestEntry, errsChan chan<-
 		}
 	}
 
+	// The output ended before some packages finished, e.g. because go test was killed
+	pkgs := []string{}
+	for _, test := range p.testsMap {
+		if !test.IsPkg() && test.Action == "" && !p.finishedPkgs[test.Pkg] && !slices.Contains(pkgs, test.Pkg) {
+			pkgs = append(pkgs, test.Pkg)
+		}
+	}
+
+	slices.Sort(pkgs)
+	for _, pkg := range pkgs {
+		p.finishRunning(pkg, p.lastTime, testsChan)
+	}
+
 	// Send remaining events that don't have a pass/skip/fail action
 	for _, test := range p.testsMap {
 		if strings.TrimSpace(test.Output) != "" {
@@ -97,8 +186,149 @@ func (p *Parser) Parse(r io.Reader, testsChan chan<- TestEntry, errsChan chan<-
 	}
 }
 
-func (p *Parser) ignoreOutput(output string) bool {
-	for _, prefix := range []string{"===", "--- PASS", "--- SKIP", "--- FAIL", "PASS"} {
+// finishRunning fails the tests of the package that started but never finished, which happens when the
+// test binary dies, e.g. because of a timeout, a crash or os.Exit.
+func (p *Parser) finishRunning(pkg string, end time.Time, testsChan chan<- TestEntry) {
+	unfinished := []*TestEntry{}
+	for _, test := range p.testsMap {
+		if test.Pkg == pkg && !test.IsPkg() && test.Action == "" && !p.hasBenchmarkResult(test) {
+			unfinished = append(unfinished, test)
+		}
+	}
+
+	// Subtests go first, so they are sent along with their root test
+	slices.SortFunc(unfinished, func(a, b *TestEntry) int {
+		if a.IsSubTest() != b.IsSubTest() {
+			if a.IsSubTest() {
+				return -1
+			}
+			return 1
+		}
+
+		if c := a.Start.Compare(b.Start); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	for _, test := range unfinished {
+		test.Action = "fail"
+		test.Unfinished = true
+		test.End = end
+		test.Elapsed = p.runningTime(test.EventID, end).Seconds()
+
+		if test.IsSubTest() {
+			key := subTestsKey(test)
+			p.subTestsMap[key] = append(p.subTestsMap[key], test)
+			continue
+		}
+
+		p.sendTest(test, testsChan)
+	}
+}
+
+// benchmarkOutput collects the benchmark results of the output, returning true when the output belongs to
+// a benchmark. Results are printed by the benchmark itself or, when it runs again with -count, by the package.
+// A result line may be split across events, so the start of the line is kept until it ends.
+func (p *Parser) benchmarkOutput(event TestEvent) (string, bool) {
+	if event.Name != "" && !strings.HasPrefix(event.Name, "Benchmark") {
+		return event.Output, false
+	}
+
+	key := event.buildID()
+	output := p.partialLines[key] + event.Output
+	if !strings.HasPrefix(output, "Benchmark") {
+		return event.Output, false
+	}
+
+	if !strings.HasSuffix(output, "\n") {
+		p.partialLines[key] = output
+		return "", true
+	}
+
+	delete(p.partialLines, key)
+
+	if benchmark, ok := ParseBenchmark(output); ok {
+		benchmark.Pkg = event.Pkg
+		p.benchmarks[event.Pkg] = append(p.benchmarks[event.Pkg], benchmark)
+		return "", true
+	}
+
+	// The name of the benchmark is printed before it runs
+	if !strings.ContainsAny(strings.TrimSpace(output), " \t") {
+		return "", true
+	}
+
+	return output, false
+}
+
+// fuzzOutput handles the output of fuzz targets, returning true when it was consumed. Progress lines are sent
+// to the renderer as they happen, and the hint go test prints to run the failing input again is replaced by
+// the renderer's own.
+func (p *Parser) fuzzOutput(test *TestEntry, output string, testsChan chan<- TestEntry) bool {
+	if !isFuzzTarget(test.RootTestName()) {
+		return false
+	}
+
+	line := strings.TrimSpace(output)
+
+	switch {
+	case strings.HasPrefix(line, "fuzz: "):
+		progress := strings.TrimPrefix(line, "fuzz: ")
+		if strings.Contains(progress, "execs: ") {
+			test.FuzzProgress = progress
+		}
+
+		testsChan <- TestEntry{Pkg: test.Pkg, Name: test.Name, EventID: test.EventID, Action: "fuzz", FuzzProgress: progress}
+		return true
+
+	case strings.HasPrefix(line, "Failing input written to "):
+		test.FuzzInput = strings.TrimPrefix(line, "Failing input written to ")
+		return true
+
+	case test.FuzzInput != "" && (line == "To re-run:" || strings.HasPrefix(line, "go test -run=")):
+		return true
+	}
+
+	return false
+}
+
+// hasBenchmarkResult reports whether the test is a benchmark that finished, since benchmarks have no pass event.
+func (p *Parser) hasBenchmarkResult(test *TestEntry) bool {
+	for _, b := range p.benchmarks[test.Pkg] {
+		if b.Name == test.Name || strings.HasPrefix(b.Name, test.Name+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runningTime returns for how long the test has been running until the given time, without the time it was paused.
+func (p *Parser) runningTime(eventID string, until time.Time) time.Duration {
+	elapsed := p.ranFor[eventID]
+
+	if resumedAt, ok := p.resumedAt[eventID]; ok && !resumedAt.IsZero() && until.After(resumedAt) {
+		elapsed += until.Sub(resumedAt)
+	}
+
+	return elapsed
+}
+
+// ignoreOutput reports whether the output only marks the progress of the tests, e.g. "=== CONT" when a
+// parallel test resumes. Those lines are indented for subtests, and newer Go versions also flag them as frames.
+func (p *Parser) ignoreOutput(event TestEvent) bool {
+	if event.OutputType == "frame" && event.Name != "" {
+		return true
+	}
+
+	if strings.HasPrefix(event.Output, "PASS") {
+		return true
+	}
+
+	output := strings.TrimLeft(event.Output, " ")
+	for _, prefix := range []string{"===", "--- PASS", "--- SKIP", "--- FAIL"} {
 		if strings.HasPrefix(output, prefix) {
 			return true
 		}
@@ -108,31 +338,55 @@ func (p *Parser) ignoreOutput(output string) bool {
 }
 
 func (p *Parser) sendTest(test *TestEntry, testsChan chan<- TestEntry) {
-	test.SubTests = p.getSubTests(test.RootTestName())
+	test.SubTests = p.getSubTests(subTestsKey(test))
 	testsChan <- *test
 	p.deleteTest(test)
 }
 
+// getSubTests returns the subtests in the order they started, parallel subtests finish in any order.
 func (p *Parser) getSubTests(key string) []TestEntry {
 	tests := make([]TestEntry, len(p.subTestsMap[key]))
 	for i, t := range p.subTestsMap[key] {
 		tests[i] = *t
 	}
 
+	slices.SortStableFunc(tests, func(a, b TestEntry) int {
+		return a.Start.Compare(b.Start)
+	})
+
 	return tests
 }
 
 func (p *Parser) deleteTest(test *TestEntry) {
 	delete(p.testsMap, test.EventID)
+	delete(p.subTestsMap, subTestsKey(test))
 
 	for _, subTest := range test.SubTests {
 		delete(p.testsMap, subTest.EventID)
 	}
 }
 
+// subTestsKey groups the subtests by package and root test, since different packages can have tests with
+// the same name.
+func subTestsKey(test *TestEntry) string {
+	return test.Pkg + " " + test.RootTestName()
+}
+
 func NewParser() *Parser {
 	testsMap := make(map[string]*TestEntry)
 	subTestsMap := make(map[string][]*TestEntry)
+	buildOutputs := make(map[string]string)
+	resumedAt := make(map[string]time.Time)
+	ranFor := make(map[string]time.Duration)
 
-	return &Parser{testsMap: testsMap, subTestsMap: subTestsMap}
+	return &Parser{
+		testsMap:     testsMap,
+		subTestsMap:  subTestsMap,
+		buildOutputs: buildOutputs,
+		resumedAt:    resumedAt,
+		ranFor:       ranFor,
+		finishedPkgs: make(map[string]bool),
+		benchmarks:   make(map[string][]Benchmark),
+		partialLines: make(map[string]string),
+	}
 }
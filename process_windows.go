@@ -0,0 +1,21 @@
+//go:build windows
+
+package main
+
+import (
+	"os/exec"
+)
+
+func startProcessGroup(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+
+	return cmd.Process.Kill()
+}
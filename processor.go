@@ -1,31 +1,244 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sync"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 )
 
 type Processor struct {
-	parser   *Parser
-	renderer *Renderer
+	config      Config
+	parser      *Parser
+	renderer    *Renderer
+	reporters   []Reporter
+	rerunnable  bool
+	rerunPassed bool
+
+	// coverProfiles are the coverage profiles of the reruns, merged into the profile of the run
+	coverProfiles []CoverProfile
+
+	// tempCoverProfile is the profile written only to compute the total coverage, when -coverprofile isn't given
+	tempCoverProfile string
 }
 
-func NewProcessor() *Processor {
-	return &Processor{parser: NewParser(), renderer: NewRenderer()}
+func NewProcessor(config Config) *Processor {
+	reporters := []Reporter{}
+
+	if config.JUnitFile != "" {
+		reporters = append(reporters, NewJUnitReporter(config.JUnitFile))
+	}
+
+	if config.JSONFile != "" {
+		reporters = append(reporters, NewJSONReporter(config.JSONFile))
+	}
+
+	if config.MarkdownFile != "" {
+		reporters = append(reporters, NewMarkdownReporter(config.MarkdownFile))
+	}
+
+	if config.HTMLFile != "" {
+		reporters = append(reporters, NewHTMLReporter(config.HTMLFile))
+	}
+
+	if config.SARIFFile != "" {
+		reporters = append(reporters, NewSARIFReporter(config.SARIFFile, NewPathResolver()))
+	}
+
+	if config.CTRFFile != "" {
+		reporters = append(reporters, NewCTRFReporter(config.CTRFFile))
+	}
+
+	if config.AllureDir != "" {
+		reporters = append(reporters, NewAllureReporter(config.AllureDir))
+	}
+
+	switch config.Format {
+	case TAPFormat:
+		reporters = append(reporters, NewTAPReporter())
+	case TeamCityFormat:
+		reporters = append(reporters, NewTeamCityReporter())
+	}
+
+	if config.GitHubActions {
+		reporters = append(reporters, NewGitHubReporter(NewPathResolver()))
+	}
+
+	quiet := config.Format != "" && config.Format != PrettyFormat
+
+	terminal := isatty.IsTerminal(os.Stdout.Fd())
+
+	renderer := NewRenderer(quiet)
+	renderer.EnableDiagnostics()
+
+	if config.Stream {
+		renderer.EnableStream(terminal)
+	}
+
+	if terminal {
+		renderer.EnableProgress()
+	}
+
+	return &Processor{config: config, parser: NewParser(), renderer: renderer, reporters: reporters}
 }
 
 func (p *Processor) Run() int {
-	if stat, _ := os.Stdin.Stat(); (stat.Mode() & os.ModeNamedPipe) != 0 {
-		return p.Process(os.Stdin)
+	cwd, _ := os.Getwd()
+	module, moduleErr := FindModule(cwd)
+
+	stat, _ := os.Stdin.Stat()
+	stdin := (stat.Mode() & os.ModeNamedPipe) != 0
+
+	// The output piped into gotestpp may come from anywhere, so it isn't recorded as the last run of the module
+	lastRun := LastRun{}
+	if moduleErr == nil && !stdin {
+		var err error
+		if lastRun, err = LoadLastRun(module); err != nil {
+			fmt.Fprintln(os.Stderr, yellow.Sprintf("failed to read the last run: %s", err))
+		}
+	}
+
+	// Same for the timings, the packages of piped output aren't sharded
+	timingsPath := p.config.TimingsFile
+	if timingsPath == "" && moduleErr == nil {
+		timingsPath = filepath.Join(module.Dir, lastRunDir, timingsFile)
+	}
+
+	timings := Timings{Packages: make(map[string]float64)}
+	if timingsPath != "" && !stdin {
+		var err error
+		if timings, err = LoadTimings(timingsPath); err != nil {
+			fmt.Fprintln(os.Stderr, yellow.Sprintf("failed to read the timings: %s", err))
+		}
+	}
+
+	code := 0
+
+	if stdin {
+		if p.config.RerunFails > 0 {
+			fmt.Fprintln(os.Stderr, yellow.Sprint("--rerun-fails is ignored when reading from stdin"))
+		}
+
+		if p.config.LastFailed {
+			fmt.Fprintln(os.Stderr, yellow.Sprint("--last-failed is ignored when reading from stdin"))
+		}
+
+		if p.config.Shard.Total > 0 {
+			fmt.Fprintln(os.Stderr, yellow.Sprint("--shard is ignored when reading from stdin"))
+		}
+
+		code = p.Process(os.Stdin)
+	} else {
+		if p.config.CoverageMin > 0 || len(p.config.CoverageMinPkgs) > 0 {
+			p.config.GoTestArgs = withCover(p.config.GoTestArgs)
+		}
+
+		if p.config.Shard.Total > 0 {
+			ok, err := p.useShard(timings)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, color.RedString("failed to list packages: %s", err))
+				return 1
+			}
+
+			if !ok {
+				return 0
+			}
+		}
+
+		if p.config.LastFailed && !p.useLastFailed(lastRun, moduleErr) {
+			return 0
+		}
+
+		code = p.runWithCmd(context.Background())
+	}
+
+	if moduleErr == nil && !stdin {
+		if err := SaveLastRun(module, lastRun, p.renderer.Run()); err != nil {
+			fmt.Fprintln(os.Stderr, yellow.Sprintf("failed to save the last run: %s", err))
+		}
+	}
+
+	if timingsPath != "" && !stdin {
+		if err := SaveTimings(timingsPath, timings, p.renderer.Run()); err != nil {
+			fmt.Fprintln(os.Stderr, yellow.Sprintf("failed to save the timings: %s", err))
+		}
+	}
+
+	return code
+}
+
+// useShard changes the go test args to run only the packages of the shard, returning false when
+// the shard has no packages.
+func (p *Processor) useShard(timings Timings) (bool, error) {
+	flags, pkgs := splitGoTestArgs(p.config.GoTestArgs)
+
+	all, err := listTestPackages(pkgs, flags)
+	if err != nil {
+		return false, err
+	}
+
+	shardPkgs := p.config.Shard.Packages(all, timings)
+	if len(shardPkgs) == 0 {
+		p.renderer.diagnosticf("%s\n", color.GreenString("No packages in shard %s", p.config.Shard))
+		return false, nil
+	}
+
+	p.config.GoTestArgs = goTestArgs(shardPkgs, flags)
+	p.renderer.diagnosticf("%s\n", blue.Sprintf("Running shard %s: %d of %d packages", p.config.Shard, len(shardPkgs), len(all)))
+
+	return true, nil
+}
+
+// useLastFailed changes the go test args to run only the tests that failed in the last run,
+// returning false when there is nothing to run.
+func (p *Processor) useLastFailed(lastRun LastRun, moduleErr error) bool {
+	if moduleErr != nil {
+		fmt.Fprintln(os.Stderr, yellow.Sprintf("--last-failed is ignored, the last run can't be found: %s", moduleErr))
+		return true
+	}
+
+	if len(lastRun.Failed) > 0 {
+		flags, _ := splitGoTestArgs(p.config.GoTestArgs)
+		p.config.GoTestArgs = lastRun.Args(flags)
+		p.renderer.diagnosticf("%s\n", blue.Sprintf("Running the %d tests that failed in the last run", len(lastRun.Failed)))
+		return true
 	}
 
-	return p.runWithCmd()
+	if p.config.LastFailedFallback == FallbackNone {
+		p.renderer.diagnosticf("%s\n", color.GreenString("No failures in the last run, nothing to run"))
+		return false
+	}
+
+	p.renderer.diagnosticf("%s\n", color.GreenString("No failures in the last run, running all tests"))
+	return true
 }
 
-func (p *Processor) Process(r io.Reader) int {
+func (p *Processor) Process(r io.Reader) (code int) {
+	if p.config.RawFile != "" {
+		raw, err := createRawFile(p.config.RawFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, color.RedString("failed to create raw file: %s", err))
+			return 1
+		}
+
+		defer func() {
+			if err := raw.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, color.RedString("failed to write raw file: %s", err))
+				code = 1
+			}
+		}()
+
+		r = io.TeeReader(r, raw)
+	}
+
 	testsChan := make(chan TestEntry)
 	errChan := make(chan error)
 
@@ -37,23 +250,101 @@ func (p *Processor) Process(r io.Reader) int {
 	}()
 
 	err := p.renderer.Render(testsChan, errChan)
-	if err != nil {
+	if errors.Is(err, ErrParseFailed) {
+		return 1
+	}
+
+	if p.rerunnable && p.config.RerunFails > 0 && errors.Is(err, ErrTestsFailed) {
+		err = p.rerunFails()
+	}
+
+	profile, hasProfile, profileErr := p.loadCoverProfile()
+	if profileErr != nil {
+		fmt.Fprintln(os.Stderr, color.RedString("failed to read the coverage profile: %s", profileErr))
+		err = errors.Join(err, profileErr)
+	}
+
+	p.renderer.PrintSummary()
+
+	if p.config.Slowest > 0 || p.config.SlowThreshold > 0 {
+		p.printSlowest()
+	}
+
+	regressed := 0
+	if p.config.BenchCompare != "" {
+		var compareErr error
+		if regressed, compareErr = p.compareBenchmarks(); compareErr != nil {
+			fmt.Fprintln(os.Stderr, color.RedString("failed to compare benchmarks: %s", compareErr))
+			err = errors.Join(err, compareErr)
+		}
+	}
+
+	if p.config.BenchSave != "" {
+		if saveErr := SaveBenchmarkBaseline(p.config.BenchSave, p.renderer.Run()); errors.Is(saveErr, ErrNoBenchmarks) {
+			fmt.Fprintln(os.Stderr, yellow.Sprint("no benchmarks to save, the baseline was not changed"))
+		} else if saveErr != nil {
+			fmt.Fprintln(os.Stderr, color.RedString("failed to save benchmarks: %s", saveErr))
+			err = errors.Join(err, saveErr)
+		}
+	}
+
+	if hasProfile {
+		if coverErr := p.reportCoverProfile(profile); coverErr != nil {
+			fmt.Fprintln(os.Stderr, color.RedString("failed to report the coverage profile: %s", coverErr))
+			err = errors.Join(err, coverErr)
+		}
+	}
+
+	belowMin := 0
+	if p.config.CoverageMin > 0 || len(p.config.CoverageMinPkgs) > 0 {
+		belowMin = p.checkCoverage()
+	}
+
+	reported := p.report()
+	if err != nil || !reported {
+		return 1
+	}
+
+	if belowMin > 0 {
+		return 1
+	}
+
+	if regressed > 0 {
+		p.renderer.diagnosticf("\n%s\n", red.Sprintf("%d benchmarks regressed more than %g%%", regressed, p.config.BenchThreshold))
 		return 1
 	}
 
+	if p.config.SlowExitCode != 0 && p.config.SlowThreshold > 0 {
+		if count := slowTestsOver(p.renderer.Run(), p.config.SlowThreshold); count > 0 {
+			p.renderer.diagnosticf("\n%s\n", red.Sprintf("%d tests took longer than %s", count, p.config.SlowThreshold))
+			return p.config.SlowExitCode
+		}
+	}
+
 	return 0
 }
 
-func (p *Processor) runWithCmd() int {
+func (p *Processor) runWithCmd(ctx context.Context) int {
 	var wg sync.WaitGroup
 	wg.Add(1)
 
 	r, w := io.Pipe()
+	p.rerunnable = true
 
-	args := os.Args[1:]
-	args = append([]string{"test", "-json"}, args...)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if p.config.FailFast {
+		p.renderer.EnableFailFast(cancel)
+	}
 
-	cmd := exec.Command("go", args...)
+	removeCoverProfile := p.useTempCoverProfile()
+	defer removeCoverProfile()
+
+	args := append([]string{"test", "-json"}, p.config.GoTestArgs...)
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	startProcessGroup(cmd)
 	cmd.Stderr = w
 	cmd.Stdout = w
 	cmd.Env = os.Environ()
@@ -75,9 +366,28 @@ func (p *Processor) runWithCmd() int {
 	w.Close()
 	wg.Wait()
 
-	if cmd.ProcessState.ExitCode() == 0 {
+	// go test was killed, so its exit code doesn't matter
+	if p.renderer.Aborted() {
+		return max(<-result, 1)
+	}
+
+	// go test fails because of the flaky tests, but they passed when rerun
+	if cmd.ProcessState.ExitCode() == 0 || p.rerunPassed {
 		return <-result
 	}
 
 	return cmd.ProcessState.ExitCode()
 }
+
+func (p *Processor) report() bool {
+	ok := true
+
+	for _, reporter := range p.reporters {
+		if err := reporter.Report(p.renderer.Run()); err != nil {
+			fmt.Fprintln(os.Stderr, color.RedString("failed to write report: %s", err))
+			ok = false
+		}
+	}
+
+	return ok
+}
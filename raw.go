@@ -0,0 +1,46 @@
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// rawFile stores the go test output as it was received, gzip compressed when the path ends with .gz.
+type rawFile struct {
+	file *os.File
+	gzip *gzip.Writer
+}
+
+func createRawFile(path string) (io.WriteCloser, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := &rawFile{file: file}
+	if strings.HasSuffix(path, ".gz") {
+		raw.gzip = gzip.NewWriter(file)
+	}
+
+	return raw, nil
+}
+
+func (r *rawFile) Write(p []byte) (int, error) {
+	if r.gzip != nil {
+		return r.gzip.Write(p)
+	}
+
+	return r.file.Write(p)
+}
+
+func (r *rawFile) Close() error {
+	var err error
+	if r.gzip != nil {
+		err = r.gzip.Close()
+	}
+
+	return errors.Join(err, r.file.Close())
+}
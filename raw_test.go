@@ -0,0 +1,42 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rawFile(t *testing.T) {
+	want, err := os.ReadFile(filepath.Join("testdata", "build_failed.txt"))
+	require.NoError(t, err)
+
+	t.Run("plain", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "raw.json")
+		processTestdata(t, NewProcessor(Config{RawFile: path}), "build_failed.txt")
+
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "raw.json.gz")
+		processTestdata(t, NewProcessor(Config{RawFile: path}), "build_failed.txt")
+
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		reader, err := gzip.NewReader(file)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	})
+}
@@ -4,8 +4,11 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"os"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -23,13 +26,30 @@ var (
 	ErrParsedWithErrors = errors.New("parsed with errors")
 )
 
+const statusInterval = 100 * time.Millisecond
+
 type Renderer struct {
+	quiet           bool
+	diagnostics     bool
+	stream          bool
+	status          bool
+	progress        bool
+	fuzzing         TestEntry
+	statusShown     bool
+	statusTime      time.Time
+	startedPkgs     []string
+	finishedPkgs    map[string]bool
+	abort           func()
+	aborted         bool
+	run             *Run
 	summary         Summary
 	failedPkgs      []string
 	failedOutputs   []string
 	skippedOutputs  []string
 	errors          []string
 	unparsedOutputs []string
+	buildOutputs    []string
+	failedBuilds    map[string]bool
 }
 
 type pkgLogs struct {
@@ -37,8 +57,40 @@ type pkgLogs struct {
 	logs         []string
 }
 
-func NewRenderer() *Renderer {
-	return &Renderer{}
+func NewRenderer(quiet bool) *Renderer {
+	return &Renderer{quiet: quiet, run: NewRun(), finishedPkgs: make(map[string]bool), failedBuilds: make(map[string]bool)}
+}
+
+func (r *Renderer) Run() *Run {
+	return r.run
+}
+
+// EnableStream prints each failure as soon as it happens instead of only at the end. When status is true,
+// a line with the progress of the run is kept at the bottom of the terminal.
+func (r *Renderer) EnableStream(status bool) {
+	r.stream = true
+	r.status = status
+}
+
+// EnableDiagnostics makes a quiet renderer still print the errors and the messages about the run, which go
+// to stderr so they don't mix with the output format.
+func (r *Renderer) EnableDiagnostics() {
+	r.diagnostics = true
+}
+
+// EnableProgress keeps a line at the bottom of the terminal with the progress of fuzz targets.
+func (r *Renderer) EnableProgress() {
+	r.progress = true
+}
+
+// EnableFailFast calls abort on the first failure, the packages that don't finish after that are
+// reported as aborted.
+func (r *Renderer) EnableFailFast(abort func()) {
+	r.abort = abort
+}
+
+func (r *Renderer) Aborted() bool {
+	return r.aborted
 }
 
 func (r *Renderer) Render(testsChan <-chan TestEntry, errChan <-chan error) error {
@@ -51,26 +103,58 @@ Loop:
 			}
 
 			switch t.Action {
+			case "start":
+				r.startedPkgs = append(r.startedPkgs, t.Pkg)
+
+			case "fuzz":
+				r.fuzzing = t
+
 			case "pass":
+				r.run.Add(t)
 				r.handlePass(t)
 
 			case "skip":
+				r.run.Add(t)
 				r.handleSkip(t)
 
 			case "fail":
+				// Tests that were running when go test was killed didn't fail, they were aborted
+				if r.aborted && t.Unfinished && !r.finishedPkgs[t.Pkg] {
+					if !slices.Contains(r.startedPkgs, t.Pkg) {
+						r.startedPkgs = append(r.startedPkgs, t.Pkg)
+					}
+					break
+				}
+
+				r.run.Add(t)
 				r.handleFail(t)
 
+				if r.abort != nil && !r.aborted {
+					r.aborted = true
+					r.abort()
+				}
+
 			default:
 				r.unparsedOutputs = append(r.unparsedOutputs, t.Output)
 			}
 
+			if t.IsPkg() && t.PkgFinished {
+				r.finishedPkgs[t.Pkg] = true
+			}
+
+			if t.Action != "fuzz" && t.EventID == r.fuzzing.EventID {
+				r.fuzzing = TestEntry{}
+			}
+
+			r.printStatus()
+
 		case err, ok := <-errChan:
 			if !ok {
 				break Loop
 			}
 
 			if len(r.errors) > 30 {
-				fmt.Println("no valid events found, if you are piping into gotestpp, go test must be run with -json flag")
+				r.diagnosticf("no valid events found, if you are piping into gotestpp, go test must be run with -json flag\n")
 				return ErrParseFailed
 			}
 
@@ -78,16 +162,37 @@ Loop:
 		}
 	}
 
+	r.clearStatus()
+
+	if r.aborted {
+		for _, pkg := range r.startedPkgs {
+			if !r.finishedPkgs[pkg] {
+				r.summary.Aborted++
+				r.failedPkgs = append(r.failedPkgs, yellow.Sprintf("ABORTED\t%s\n", pkg))
+			}
+		}
+	}
+
+	r.printBenchmarks()
+
+	recap := len(r.failedPkgs) + len(r.skippedOutputs) + len(r.failedOutputs) + len(r.unparsedOutputs) + len(r.buildOutputs) + len(r.errors)
+	if r.stream && recap > 0 {
+		r.printf("\n%s\n", blue.Sprint("Recap:"))
+	}
+
 	r.printFailedPkgs()
 	r.printSkipped()
 	r.printFailures()
 	r.printUnparsed()
+	r.printBuildOutputs()
 	r.printErrors()
 
-	fmt.Printf("\n%s\n", r.summary)
+	r.run.Summary = r.summary
+	r.run.Errors = r.errors
+	r.run.Unparsed = r.unparsedOutputs
 
 	switch {
-	case r.summary.Failed > 0:
+	case r.summary.Failed > 0 || len(r.buildOutputs) > 0:
 		return ErrTestsFailed
 	case len(r.errors) > 0:
 		return ErrParsedWithErrors
@@ -102,30 +207,34 @@ func (r *Renderer) handlePass(t TestEntry) {
 		return
 	}
 
+	if t.NoTestFiles {
+		r.printf("%s\t%s\t[no test files]%s\n", color.YellowString("?"), t.Pkg, formatCoverage(t))
+		return
+	}
+
 	if t.Cached {
-		fmt.Printf("%s\t%s\t(cached)\n", color.GreenString("ok"), t.Pkg)
+		r.printf("%s\t%s\t(cached)%s\n", color.GreenString("ok"), t.Pkg, formatCoverage(t))
 		return
 	}
 
 	r.summary.Elapsed += t.Elapsed
 
 	if t.PkgFinished {
-		fmt.Printf("%s\t%s\t%.2fs\n", color.GreenString("ok"), t.Pkg, t.Elapsed)
+		r.printf("%s\t%s\t%.2fs%s\n", color.GreenString("ok"), t.Pkg, t.Elapsed, formatCoverage(t))
 	}
 }
 
 func (r *Renderer) handleSkip(t TestEntry) {
 	if t.NoTestFiles {
-		fmt.Printf("%s\t%s\t[no test files]\n", color.YellowString("?"), t.Pkg)
+		r.printf("%s\t%s\t[no test files]\n", color.YellowString("?"), t.Pkg)
 		return
 	}
 
 	r.summary.Elapsed += t.Elapsed
 	r.summary.Skipped++
 
-	file := strings.TrimSpace(t.Output)
 	output := yellow.Sprintf("%s %s (%.2fs)\n", "--- SKIP", t.Name, t.Elapsed)
-	output += yellow.Sprintf("\t%s\n", strings.TrimSuffix(file, ":"))
+	output += yellow.Sprintf("\t%s\n", t.SkipReason())
 
 	r.skippedOutputs = append(r.skippedOutputs, output)
 }
@@ -133,6 +242,14 @@ func (r *Renderer) handleSkip(t TestEntry) {
 func (r *Renderer) handleFail(t TestEntry) {
 	if t.BuildFailed {
 		r.failedPkgs = append(r.failedPkgs, red.Sprintf("FAIL\t%s\t[build failed]\n", t.Pkg))
+		r.streamOutput(r.failedPkgs[len(r.failedPkgs)-1])
+
+		// Newer Go versions report the build output as events instead of plain text, every package that
+		// depends on the broken one gets the same output
+		if output := strings.TrimSpace(t.Output); output != "" && !r.failedBuilds[t.FailedBuild] {
+			r.failedBuilds[t.FailedBuild] = true
+			r.buildOutputs = append(r.buildOutputs, output)
+		}
 		return
 	}
 
@@ -141,15 +258,121 @@ func (r *Renderer) handleFail(t TestEntry) {
 
 		output := fmt.Sprintf("%s\t%s\n", color.RedString("FAIL"), t.Pkg)
 		r.failedPkgs = append(r.failedPkgs, output)
+		r.streamOutput(output)
 		return
 	}
 
 	r.summary.Failed += 1 + len(t.FilterSubTestsByAction("fail"))
 	r.summary.Passed += len(t.FilterSubTestsByAction("pass"))
-	r.failedOutputs = append(r.failedOutputs, r.formatError(t))
+	r.failedOutputs = append(r.failedOutputs, formatError(t))
+	r.streamOutput("\n" + r.failedOutputs[len(r.failedOutputs)-1] + "\n")
+}
+
+func (r *Renderer) streamOutput(output string) {
+	if r.stream {
+		r.print(output)
+	}
+}
+
+func (r *Renderer) printStatus() {
+	if r.quiet || (r.statusShown && time.Since(r.statusTime) < statusInterval) {
+		return
+	}
+
+	var status string
+	switch {
+	case r.progress && r.fuzzing.Name != "":
+		status = fmt.Sprintf("fuzzing %s: %s", r.fuzzing.Name, r.fuzzing.FuzzProgress)
+
+	case r.status:
+		running := max(len(r.startedPkgs)-len(r.finishedPkgs), 0)
+		status = fmt.Sprintf("%d running, %d finished packages | %d passed, %d failed, %d skipped",
+			running, len(r.finishedPkgs), r.summary.Passed, r.summary.Failed, r.summary.Skipped)
+
+	default:
+		return
+	}
+
+	fmt.Print("\r\033[K" + blue.Sprint(status))
+	r.statusShown = true
+	r.statusTime = time.Now()
+}
+
+func (r *Renderer) clearStatus() {
+	if r.statusShown {
+		fmt.Print("\r\033[K")
+		r.statusShown = false
+	}
+}
+
+// outputStyle colors the formatted output of the tests, with ANSI colors in the terminal and spans in the HTML report.
+type outputStyle struct {
+	text  func(s string) string
+	color func(attr color.Attribute, s string) string
+}
+
+var terminalStyle = outputStyle{
+	text:  func(s string) string { return s },
+	color: func(attr color.Attribute, s string) string { return color.New(attr).Sprint(s) },
+}
+
+func formatError(t TestEntry) string {
+	output := fmt.Sprintf("%s %s (%.2fs)\n", color.RedString("--- FAIL"), t.Name, t.Elapsed)
+
+	if t.Unfinished {
+		output = fmt.Sprintf("%s %s (%s, ran for %.2fs)\n", color.RedString("--- DID NOT FINISH"), t.Name, unfinishedState(t), t.Elapsed)
+	}
+
+	details := styledDetails(t, terminalStyle)
+	if details != "" {
+		output += details + "\n"
+	}
+
+	failedSubTests := t.FilterSubTestsByAction("fail")
+
+	if len(failedSubTests) > 0 && details != "" {
+		output += "\n"
+	}
+
+	subTestsOutput := make([]string, len(failedSubTests))
+	for i, st := range failedSubTests {
+		subTestsOutput[i] = formatError(st)
+	}
+
+	output += strings.Join(subTestsOutput, "\n")
+
+	return output
+}
+
+func unfinishedState(t TestEntry) string {
+	if t.Paused {
+		return "paused"
+	}
+
+	return "running"
+}
+
+// styledDetails formats the output of a single test, without its subtests, followed by its failing fuzz input.
+func styledDetails(t TestEntry, style outputStyle) string {
+	details := []string{}
+
+	if output := styledOutput(t, style); output != "" {
+		details = append(details, output)
+	}
+
+	if t.FuzzInput != "" {
+		details = append(details, styledFuzzInput(t, style))
+	}
+
+	return strings.Join(details, "\n")
+}
+
+// formatOutput formats the output of a single test, without its subtests.
+func formatOutput(t TestEntry) string {
+	return styledOutput(t, terminalStyle)
 }
 
-func (r *Renderer) formatError(t TestEntry) string {
+func styledOutput(t TestEntry, style outputStyle) string {
 	outputLines := []string{}
 	reader := strings.NewReader(t.Output)
 	scanner := NewRewindScanner(bufio.NewScanner(reader))
@@ -169,71 +392,109 @@ func (r *Renderer) formatError(t TestEntry) string {
 		case IsTestifyAssert(line):
 			nonTrimmed := scanner.Text()
 			testifyAssert := NewTestifyAssert(nonTrimmed, scanner)
-			outputLines = append(outputLines, testifyAssert.String())
+			outputLines = append(outputLines, testifyAssert.styled(style))
 
 		case t.Panicked && panicStarted:
+			prefix := ""
 			if panicFileRe.MatchString(line) {
-				line = "\t" + line
+				prefix = "\t"
 			}
-			outputLines = append(outputLines, color.RedString(line))
+			outputLines = append(outputLines, prefix+style.color(color.FgRed, line))
 
 		default:
 			if matches := errorFileRe.FindStringSubmatch(line); len(matches) > 0 {
-				line = "\t" + color.CyanString(matches[1]) + color.RedString(matches[2])
+				line = "\t" + style.color(color.FgCyan, matches[1]) + style.color(color.FgRed, matches[2])
 				outputLines = append(outputLines, line)
 				continue
 			}
 
-			outputLines = append(outputLines, scanner.Text())
+			outputLines = append(outputLines, style.text(scanner.Text()))
 		}
 	}
 
-	output := fmt.Sprintf("%s %s (%.2fs)\n", color.RedString("--- FAIL"), t.Name, t.Elapsed)
-
-	if len(outputLines) > 0 {
-		output += fmt.Sprintf("%s\n", strings.Join(outputLines, "\n"))
-	}
+	return strings.Join(outputLines, "\n")
+}
 
-	failedSubTests := t.FilterSubTestsByAction("fail")
+func (r Renderer) PrintSummary() {
+	r.printf("\n%s\n", r.run.Summary)
+}
 
-	if len(failedSubTests) > 0 && len(outputLines) > 0 {
-		output += "\n"
+func (r *Renderer) printBenchmarks() {
+	pkgs := []*PackageRun{}
+	for _, pkg := range r.run.Packages {
+		if len(pkg.Entry.Benchmarks) > 0 {
+			pkgs = append(pkgs, pkg)
+		}
 	}
 
-	subTestsOutput := make([]string, len(failedSubTests))
-	for i, st := range failedSubTests {
-		subTestsOutput[i] = r.formatError(st)
+	if len(pkgs) == 0 {
+		return
 	}
 
-	output += strings.Join(subTestsOutput, "\n")
-
-	return output
+	r.printf("\n%s\n", blue.Sprint("Benchmarks:"))
+	for _, pkg := range pkgs {
+		r.printf("\n%s\n", pkg.Name)
+		for _, row := range benchmarkTable(pkg.Entry.Benchmarks) {
+			r.printf("\t%s\n", row)
+		}
+	}
 }
 
 func (r Renderer) printFailedPkgs() {
-	fmt.Print(strings.Join(r.failedPkgs, ""))
+	r.print(strings.Join(r.failedPkgs, ""))
 }
 
 func (r Renderer) printSkipped() {
 	if len(r.skippedOutputs) > 0 {
-		fmt.Print("\n" + strings.Join(r.skippedOutputs, "\n"))
+		r.print("\n" + strings.Join(r.skippedOutputs, "\n"))
 	}
 }
 
 func (r Renderer) printFailures() {
 	if len(r.failedOutputs) > 0 {
-		fmt.Print("\n" + strings.Join(r.failedOutputs, "\n"))
+		r.print("\n" + strings.Join(r.failedOutputs, "\n"))
 	}
 }
 
 func (r Renderer) printUnparsed() {
 	if len(r.unparsedOutputs) > 0 {
-		fmt.Printf("\n%s\n%s", color.BlueString("Unparsed:"), strings.Join(r.unparsedOutputs, "\n"))
+		r.diagnosticf("\n%s\n%s", color.BlueString("Unparsed:"), strings.Join(r.unparsedOutputs, "\n"))
+	}
+}
+
+func (r Renderer) printBuildOutputs() {
+	if len(r.buildOutputs) > 0 {
+		r.diagnosticf("\n%s\n%s\n", color.RedString("Build errors:"), strings.Join(r.buildOutputs, "\n"))
 	}
 }
 
 func (r Renderer) printErrors() {
 	if len(r.errors) > 0 {
-		fmt.Printf("\n%s\n%s\n", color.RedString("Errors:"), strings.Join(r.errors, "\n"))
+		r.diagnosticf("\n%s\n%s\n", color.RedString("Errors:"), strings.Join(r.errors, "\n"))
+	}
+}
+
+func (r *Renderer) printf(format string, a ...any) {
+	if !r.quiet {
+		r.clearStatus()
+		fmt.Printf(format, a...)
+	}
+}
+
+// diagnosticf prints what explains the result of the run, like errors and failed checks, which a quiet
+// renderer with diagnostics enabled prints to stderr.
+func (r *Renderer) diagnosticf(format string, a ...any) {
+	switch {
+	case !r.quiet:
+		r.printf(format, a...)
+	case r.diagnostics:
+		fmt.Fprintf(os.Stderr, format, a...)
+	}
+}
+
+func (r *Renderer) print(a ...any) {
+	if !r.quiet {
+		r.clearStatus()
+		fmt.Print(a...)
 	}
 }
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+type Reporter interface {
+	Report(run *Run) error
+}
+
+type Run struct {
+	Packages []*PackageRun
+	Summary  Summary
+	Errors   []string
+	Unparsed []string
+	Flaky    []FlakyTest
+	pkgs     map[string]*PackageRun
+}
+
+type PackageRun struct {
+	Name  string
+	Entry TestEntry
+	Tests []TestEntry
+}
+
+func NewRun() *Run {
+	return &Run{pkgs: make(map[string]*PackageRun)}
+}
+
+func (r *Run) Add(t TestEntry) {
+	pkg := r.Package(t.Pkg)
+
+	if t.IsPkg() {
+		pkg.Entry = t
+		return
+	}
+
+	pkg.Tests = append(pkg.Tests, t)
+}
+
+func (r *Run) Package(name string) *PackageRun {
+	pkg, ok := r.pkgs[name]
+	if !ok {
+		pkg = &PackageRun{Name: name}
+		r.pkgs[name] = pkg
+		r.Packages = append(r.Packages, pkg)
+	}
+
+	return pkg
+}
+
+// BuildOutput returns the compiler output of a package that failed to build. Newer Go versions
+// attach it to the package itself, older ones print it as plain text, which ends up in the errors.
+func (r *Run) BuildOutput(pkg string) string {
+	if entry := r.pkgs[pkg]; entry != nil && strings.TrimSpace(entry.Entry.Output) != "" {
+		return entry.Entry.Output
+	}
+
+	output := ""
+	inPkg := false
+
+	for _, line := range r.Errors {
+		if header, ok := strings.CutPrefix(line, "# "); ok {
+			inPkg = buildHeaderPkg(header) == pkg
+		}
+
+		if inPkg {
+			output += line + "\n"
+		}
+	}
+
+	return output
+}
+
+// Start returns when the first package started, it's zero if the events had no time.
+func (r *Run) Start() time.Time {
+	start := time.Time{}
+	for _, pkg := range r.Packages {
+		if !pkg.Entry.Start.IsZero() && (start.IsZero() || pkg.Entry.Start.Before(start)) {
+			start = pkg.Entry.Start
+		}
+	}
+
+	return start
+}
+
+func (r *Run) End() time.Time {
+	end := time.Time{}
+	for _, pkg := range r.Packages {
+		if pkg.Entry.End.After(end) {
+			end = pkg.Entry.End
+		}
+	}
+
+	return end
+}
+
+func (p *PackageRun) Failed() bool {
+	return p.Entry.Action == "fail"
+}
+
+// Flaky tells whether every test that made the package fail passed when rerun.
+func (p *PackageRun) Flaky() bool {
+	failedTests := p.FailedTests()
+	if !p.Failed() || p.Entry.BuildFailed || len(failedTests) == 0 {
+		return false
+	}
+
+	for _, t := range failedTests {
+		if !t.Flaky {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Status is the action of the package, which passed when it failed only because of flaky tests.
+func (p *PackageRun) Status() string {
+	if p.Flaky() {
+		return "pass"
+	}
+
+	return p.Entry.Action
+}
+
+func (p *PackageRun) FailedTests() []TestEntry {
+	tests := []TestEntry{}
+	for _, t := range p.Tests {
+		if t.Action == "fail" {
+			tests = append(tests, t)
+		}
+	}
+
+	return tests
+}
+
+// Benchmarks returns the benchmark results of every package, in the order they ran.
+func (r *Run) Benchmarks() []Benchmark {
+	benchmarks := []Benchmark{}
+	for _, pkg := range r.Packages {
+		benchmarks = append(benchmarks, pkg.Entry.Benchmarks...)
+	}
+
+	return benchmarks
+}
+
+// AllTests returns the tests of the package, each one followed by its subtests.
+func (p *PackageRun) AllTests() []TestEntry {
+	tests := []TestEntry{}
+	for _, t := range p.Tests {
+		tests = append(tests, t)
+		tests = append(tests, t.SubTests...)
+	}
+
+	return tests
+}
+
+// buildHeaderPkg extracts the package from a build output header, which looks like
+// "pkg_test [pkg.test]" when building tests or just "pkg" otherwise.
+func buildHeaderPkg(header string) string {
+	name, bracket, ok := strings.Cut(header, " [")
+	if !ok {
+		return name
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(bracket, "]"), ".test")
+}
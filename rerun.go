@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+type FlakyTest struct {
+	Pkg     string
+	Name    string
+	Attempt int
+}
+
+type rerunTest struct {
+	pkg     string
+	name    string
+	attempt int
+}
+
+// rerunFails reruns the failed tests up to the configured number of times, marking the ones that pass as flaky.
+// It returns the error the run should end with once the flaky tests are no longer considered failures.
+func (p *Processor) rerunFails() error {
+	run := p.renderer.Run()
+	pending := []*rerunTest{}
+
+	for _, pkg := range run.Packages {
+		for _, t := range pkg.FailedTests() {
+			for _, name := range rerunNames(t, t) {
+				pending = append(pending, &rerunTest{pkg: pkg.Name, name: name})
+			}
+		}
+	}
+
+	tests := pending
+	flags, _ := splitGoTestArgs(p.config.GoTestArgs)
+
+	for attempt := 1; attempt <= p.config.RerunFails && len(pending) > 0; attempt++ {
+		p.renderer.diagnosticf("\n%s\n", blue.Sprintf("Rerunning %d failed tests (attempt %d of %d)", len(pending), attempt, p.config.RerunFails))
+
+		byPkg := map[string][]*rerunTest{}
+		pkgs := []string{}
+		for _, t := range pending {
+			if _, ok := byPkg[t.pkg]; !ok {
+				pkgs = append(pkgs, t.pkg)
+			}
+			byPkg[t.pkg] = append(byPkg[t.pkg], t)
+		}
+
+		pending = []*rerunTest{}
+
+		for _, pkg := range pkgs {
+			names := []string{}
+			for _, t := range byPkg[pkg] {
+				names = append(names, t.name)
+			}
+
+			// -count=1 makes sure the tests run again instead of using cached results
+			extra := []string{"-count=1", "-run=" + runPattern(names)}
+
+			// The rerun would overwrite the coverage profile of the run, so it writes its own to be merged later
+			coverProfile := ""
+			if coverProfilePath(p.config.GoTestArgs) != "" {
+				if file, err := os.CreateTemp("", "gotestpp-*.cover"); err == nil {
+					file.Close()
+					coverProfile = file.Name()
+					extra = append(extra, "-coverprofile="+coverProfile)
+				}
+			}
+
+			rerun := &Processor{
+				config:   Config{GoTestArgs: goTestArgs([]string{pkg}, flags, extra...)},
+				parser:   NewParser(),
+				renderer: NewRenderer(true),
+			}
+			rerun.runWithCmd(context.Background())
+
+			if coverProfile != "" {
+				if profile, err := LoadCoverProfile(coverProfile); err == nil {
+					p.coverProfiles = append(p.coverProfiles, profile)
+				}
+				os.Remove(coverProfile)
+			}
+
+			passed := map[string]bool{}
+			for _, t := range rerun.renderer.Run().Package(pkg).AllTests() {
+				passed[t.Name] = t.Action == "pass"
+			}
+
+			for _, t := range byPkg[pkg] {
+				if passed[t.name] {
+					t.attempt = attempt
+				} else {
+					pending = append(pending, t)
+				}
+			}
+		}
+	}
+
+	p.markFlaky(tests)
+
+	return p.result()
+}
+
+func (p *Processor) markFlaky(tests []*rerunTest) {
+	run := p.renderer.Run()
+
+	for _, t := range tests {
+		if t.attempt > 0 {
+			run.Flaky = append(run.Flaky, FlakyTest{Pkg: t.pkg, Name: t.name, Attempt: t.attempt})
+		}
+	}
+
+	// A failed test is flaky when every rerun related to it passed, that includes its failed subtests
+	// and, when the whole test was rerun, the test itself
+	for _, pkg := range run.Packages {
+		mark := func(t *TestEntry) {
+			if t.Action != "fail" {
+				return
+			}
+
+			related := 0
+			flaky := true
+			for _, rt := range tests {
+				if rt.pkg == pkg.Name && isRelatedTest(rt.name, t.Name) {
+					related++
+					flaky = flaky && rt.attempt > 0
+				}
+			}
+
+			if related > 0 && flaky {
+				t.Flaky = true
+				run.Summary.Failed--
+				run.Summary.Flaky++
+			}
+		}
+
+		for i := range pkg.Tests {
+			mark(&pkg.Tests[i])
+			for j := range pkg.Tests[i].SubTests {
+				mark(&pkg.Tests[i].SubTests[j])
+			}
+		}
+	}
+
+	if len(run.Flaky) > 0 {
+		p.renderer.diagnosticf("\n%s\n", yellow.Sprint("Flaky tests:"))
+		for _, t := range run.Flaky {
+			p.renderer.diagnosticf("%s\n", yellow.Sprintf("\t%s %s (passed on attempt %d)", t.Pkg, t.Name, t.Attempt))
+		}
+	}
+
+	failed := []string{}
+	for _, t := range tests {
+		if t.attempt == 0 {
+			failed = append(failed, color.RedString("\t%s %s", t.pkg, t.name))
+		}
+	}
+
+	if len(failed) > 0 {
+		p.renderer.diagnosticf("\n%s\n%s\n", red.Sprintf("Failed on every attempt:"), strings.Join(failed, "\n"))
+	}
+}
+
+// result checks whether anything besides flaky tests failed.
+func (p *Processor) result() error {
+	run := p.renderer.Run()
+
+	if run.Summary.Failed > 0 {
+		return ErrTestsFailed
+	}
+
+	for _, pkg := range run.Packages {
+		// Packages may fail without a failed test, e.g. when TestMain exits with an error
+		if pkg.Failed() && (pkg.Entry.BuildFailed || len(pkg.FailedTests()) == 0) {
+			return ErrTestsFailed
+		}
+	}
+
+	if len(run.Errors) > 0 {
+		return ErrParsedWithErrors
+	}
+
+	p.rerunPassed = true
+
+	return nil
+}
+
+// rerunNames returns the names of the tests to rerun so that t passes, which are its deepest failed
+// subtests, unless the test has failures of its own, in which case all of it has to run again.
+func rerunNames(root TestEntry, t TestEntry) []string {
+	failedSubTests := []TestEntry{}
+	for _, st := range root.DirectSubTests(t.Name) {
+		if st.Action == "fail" {
+			failedSubTests = append(failedSubTests, st)
+		}
+	}
+
+	if len(failedSubTests) == 0 || len(TestLocations(t)) > 0 {
+		return []string{t.Name}
+	}
+
+	names := []string{}
+	for _, st := range failedSubTests {
+		names = append(names, rerunNames(root, st)...)
+	}
+
+	return names
+}
+
+func isRelatedTest(a string, b string) bool {
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// runPattern builds a -run pattern matching exactly the given tests, e.g. ^TestA$/^sub$|^TestB$.
+func runPattern(names []string) string {
+	patterns := make([]string, len(names))
+	for i, name := range names {
+		parts := strings.Split(name, "/")
+		for j, part := range parts {
+			parts[j] = "^" + regexp.QuoteMeta(part) + "$"
+		}
+
+		patterns[i] = strings.Join(parts, "/")
+	}
+
+	return strings.Join(patterns, "|")
+}
+
+// goTestArgs builds the go test args for the packages, keeping the flags given by the user except the ones
+// overridden by extra, which must use the -name=value form.
+func goTestArgs(pkgs []string, flags []string, extra ...string) []string {
+	args := slices.Clone(pkgs)
+	binaryArgs := []string{}
+
+	overridden := []string{}
+	for _, flag := range extra {
+		name, _, _ := strings.Cut(strings.TrimLeft(flag, "-"), "=")
+		overridden = append(overridden, name)
+	}
+
+	for i := 0; i < len(flags); i++ {
+		flag := flags[i]
+
+		if flag == "-args" || flag == "--args" {
+			binaryArgs = flags[i:]
+			break
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimLeft(flag, "-"), "=")
+		if slices.Contains(overridden, name) {
+			if !hasValue && !slices.Contains(goTestBoolFlags, name) {
+				i++
+			}
+			continue
+		}
+
+		args = append(args, flag)
+	}
+
+	args = append(args, extra...)
+
+	return append(args, binaryArgs...)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rerunNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		want     []string
+	}{
+		{"subtest fail", "subtest_fail.txt", []string{"TestExpenseService_Create/handle_float_precision_edge_cases"}},
+		{"fail and subtest fail", "fail_and_subtest_fail.txt", []string{"TestExpenseService_Create"}},
+		{"fail", "fail.txt", []string{"TestExpenseService_Create"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewProcessor(Config{})
+			processTestdata(t, processor, tt.fileName)
+
+			names := []string{}
+			for _, pkg := range processor.renderer.Run().Packages {
+				for _, ft := range pkg.FailedTests() {
+					names = append(names, rerunNames(ft, ft)...)
+				}
+			}
+
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func Test_runPattern(t *testing.T) {
+	pattern := runPattern([]string{"TestA/sub_one/deep", "TestB", "TestC/case_#01"})
+	assert.Equal(t, `^TestA$/^sub_one$/^deep$|^TestB$|^TestC$/^case_#01$`, pattern)
+
+	assert.Equal(t, `^Test\.Dot$/^a\(b\)$`, runPattern([]string{"Test.Dot/a(b)"}))
+}
+
+func Test_goTestArgs(t *testing.T) {
+	flags := []string{"-race", "-run", "TestA", "-count=3", "-timeout", "1m", "-args", "-run", "x"}
+
+	args := goTestArgs([]string{"example.com/pkg"}, flags, "-count=1", "-run=^TestA$")
+	assert.Equal(t, []string{"example.com/pkg", "-race", "-timeout", "1m", "-count=1", "-run=^TestA$", "-args", "-run", "x"}, args)
+
+	args = goTestArgs([]string{"./a", "./b"}, []string{"-v", "-run", "TestA"})
+	assert.Equal(t, []string{"./a", "./b", "-v", "-run", "TestA"}, args)
+}
+
+func Test_flakyReports(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	processor := NewProcessor(Config{})
+	processTestdata(t, processor, "fail.txt")
+
+	captureOutput(func() {
+		processor.markFlaky([]*rerunTest{{pkg: "github.com/joaopsramos/fincon/internal/service", name: "TestExpenseService_Create", attempt: 1}})
+	})
+
+	run := processor.renderer.Run()
+	a.Equal(0, run.Summary.Failed)
+	a.Equal(1, run.Summary.Flaky)
+
+	dir := t.TempDir()
+
+	t.Run("junit", func(t *testing.T) {
+		path := filepath.Join(dir, "report.xml")
+		r.NoError(NewJUnitReporter(path).Report(run))
+
+		var report junitTestSuites
+		r.NoError(xml.Unmarshal(mustReadFile(t, path), &report))
+		a.Equal(0, report.Failures)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		path := filepath.Join(dir, "report.json")
+		r.NoError(NewJSONReporter(path).Report(run))
+
+		var report jsonReport
+		r.NoError(json.Unmarshal(mustReadFile(t, path), &report))
+
+		for _, pkg := range report.Packages {
+			a.NotEqual("fail", pkg.Status, pkg.Name)
+
+			for _, test := range pkg.Tests {
+				a.NotEqual("fail", test.Status, test.Name)
+				a.Equal(test.Name == "TestExpenseService_Create", test.Flaky, test.Name)
+			}
+		}
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		path := filepath.Join(dir, "report.sarif")
+		r.NoError(NewSARIFReporter(path, NewPathResolver()).Report(run))
+
+		var report sarifLog
+		r.NoError(json.Unmarshal(mustReadFile(t, path), &report))
+		a.Empty(report.Runs[0].Results)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		path := filepath.Join(dir, "report.md")
+		r.NoError(NewMarkdownReporter(path).Report(run))
+
+		content := string(mustReadFile(t, path))
+		a.NotContains(content, "Failed tests")
+		a.Contains(content, "| `github.com/joaopsramos/fincon/internal/service` | :warning: flaky |")
+	})
+
+	t.Run("tap", func(t *testing.T) {
+		output := captureOutput(func() { NewTAPReporter().Report(run) })
+		a.NotContains(output, "not ok")
+	})
+
+	t.Run("teamcity", func(t *testing.T) {
+		output := captureOutput(func() { NewTeamCityReporter().Report(run) })
+		a.NotContains(output, "testFailed")
+	})
+
+	t.Run("github", func(t *testing.T) {
+		output := captureOutput(func() { NewGitHubReporter(NewPathResolver()).Report(run) })
+		a.Empty(output)
+	})
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return content
+}
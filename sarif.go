@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifFailure is the kind of the failures without a location, like a TestMain that exits with an error
+const sarifFailure LocationKind = "failure"
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+var sarifRules = map[LocationKind]sarifRule{
+	AssertLocation:  {ID: "go-test/assertion", Name: "AssertionFailure", ShortDescription: sarifMessage{"Test assertion failed"}},
+	PanicLocation:   {ID: "go-test/panic", Name: "Panic", ShortDescription: sarifMessage{"Test panicked"}},
+	TimeoutLocation: {ID: "go-test/timeout", Name: "Timeout", ShortDescription: sarifMessage{"Test timed out"}},
+	BuildLocation:   {ID: "go-test/build", Name: "BuildFailure", ShortDescription: sarifMessage{"Package failed to build"}},
+	sarifFailure:    {ID: "go-test/failure", Name: "Failure", ShortDescription: sarifMessage{"Test or package failed"}},
+}
+
+var sarifRulesOrder = []LocationKind{AssertLocation, PanicLocation, TimeoutLocation, BuildLocation, sarifFailure}
+
+type SARIFReporter struct {
+	path     string
+	resolver *PathResolver
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func NewSARIFReporter(path string, resolver *PathResolver) *SARIFReporter {
+	return &SARIFReporter{path: path, resolver: resolver}
+}
+
+func (s *SARIFReporter) Report(run *Run) error {
+	rules := make([]sarifRule, len(sarifRulesOrder))
+	for i, kind := range sarifRulesOrder {
+		rules[i] = sarifRules[kind]
+	}
+
+	result := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "gotestpp",
+			InformationURI: "https://github.com/joaopsramos/gotestpp",
+			Rules:          rules,
+		}},
+		Results: []sarifResult{},
+	}
+
+	for _, pkg := range run.Packages {
+		if pkg.Entry.BuildFailed {
+			locations := BuildLocations(run.BuildOutput(pkg.Name))
+			if len(locations) == 0 {
+				locations = []Location{{Kind: BuildLocation, Message: "build failed"}}
+			}
+
+			for _, location := range locations {
+				message := fmt.Sprintf("%s: %s", pkg.Name, location.Message)
+				result.Results = append(result.Results, s.buildResult(pkg.Name, location, message))
+			}
+			continue
+		}
+
+		failedTests := 0
+		for _, t := range pkg.AllTests() {
+			if t.Status() != "fail" {
+				continue
+			}
+			failedTests++
+
+			locations := TestLocations(t)
+
+			// Parent tests usually fail only because of their subtests, which are reported on their own
+			if len(locations) == 0 && len(t.FilterSubTestsByAction("fail")) == 0 {
+				message := "test failed"
+				if t.Unfinished {
+					message = "did not finish"
+				}
+
+				locations = []Location{{Kind: sarifFailure, Message: message}}
+			}
+
+			for _, location := range locations {
+				message := fmt.Sprintf("%s: %s", t.Name, location.Message)
+				result.Results = append(result.Results, s.buildResult(pkg.Name, location, message))
+			}
+		}
+
+		if pkg.Status() != "fail" {
+			continue
+		}
+
+		// Panics and timeouts outside of a test are reported by the package itself
+		reported := false
+		for _, location := range TestLocations(pkg.Entry) {
+			if location.Kind != AssertLocation {
+				result.Results = append(result.Results, s.buildResult(pkg.Name, location, pkg.Name+": "+location.Message))
+				reported = true
+			}
+		}
+
+		if !reported && failedTests == 0 {
+			location := Location{Kind: sarifFailure, Message: "package failed"}
+			result.Results = append(result.Results, s.buildResult(pkg.Name, location, pkg.Name+": "+location.Message))
+		}
+	}
+
+	sarif := sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{result}}
+
+	output, err := json.MarshalIndent(sarif, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, append(output, '\n'), 0o644)
+}
+
+func (s *SARIFReporter) buildResult(pkg string, location Location, message string) sarifResult {
+	result := sarifResult{
+		RuleID:  sarifRules[location.Kind].ID,
+		Level:   "error",
+		Message: sarifMessage{Text: message},
+	}
+
+	result.RuleIndex = slices.Index(sarifRulesOrder, location.Kind)
+
+	if file, ok := s.resolver.Resolve(pkg, location.File); ok {
+		physical := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: file, URIBaseID: "%SRCROOT%"}}
+		if location.Line > 0 {
+			physical.Region = &sarifRegion{StartLine: location.Line}
+		}
+
+		result.Locations = []sarifLocation{{PhysicalLocation: physical}}
+	}
+
+	return result
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sarifReport(t *testing.T) {
+	resolver := &PathResolver{
+		module:    Module{Path: "github.com/joaopsramos/fincon", Dir: "/work/fincon/backend"},
+		workspace: "/work/fincon",
+		cwd:       "/work/fincon/backend",
+	}
+
+	tests := []struct {
+		name     string
+		fileName string
+		want     []sarifResult
+	}{
+		{"fail", "fail.txt", []sarifResult{{
+			RuleID:    "go-test/assertion",
+			RuleIndex: 0,
+			Level:     "error",
+			Message:   sarifMessage{"TestExpenseService_Create: 2 should be equal to 1"},
+			Locations: sarifLocations("backend/internal/service/expense_test.go", 213),
+		}}},
+		{"panic", "panic.txt", []sarifResult{{
+			RuleID:    "go-test/panic",
+			RuleIndex: 1,
+			Level:     "error",
+			Message:   sarifMessage{"TestPostgresExpense_GetSummary: panic: something went really wrong [recovered]"},
+			Locations: sarifLocations("backend/internal/service/expense_test.go", 33),
+		}, {
+			RuleID:    "go-test/failure",
+			RuleIndex: 4,
+			Level:     "error",
+			Message:   sarifMessage{"TestExpenseService_Create: did not finish"},
+		}, {
+			RuleID:    "go-test/failure",
+			RuleIndex: 4,
+			Level:     "error",
+			Message:   sarifMessage{"TestExpenseService_UpdateByID: did not finish"},
+		}}},
+		{"build failed", "build_failed.txt", []sarifResult{{
+			RuleID:    "go-test/build",
+			RuleIndex: 3,
+			Level:     "error",
+			Message:   sarifMessage{"github.com/joaopsramos/fincon/internal/service: undefined: pan"},
+			Locations: sarifLocations("backend/internal/service/expense_test.go", 203),
+		}}},
+		{"no location", "no_location.txt", []sarifResult{{
+			RuleID:    "go-test/build",
+			RuleIndex: 3,
+			Level:     "error",
+			Message:   sarifMessage{"example.com/mt/cyc2: build failed"},
+		}, {
+			RuleID:    "go-test/failure",
+			RuleIndex: 4,
+			Level:     "error",
+			Message:   sarifMessage{"example.com/mt/exit: package failed"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.sarif")
+			processor := NewProcessor(Config{})
+			processor.reporters = []Reporter{NewSARIFReporter(path, resolver)}
+
+			processTestdata(t, processor, tt.fileName)
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			var log sarifLog
+			require.NoError(t, json.Unmarshal(content, &log))
+
+			assert.Equal(t, "2.1.0", log.Version)
+			require.Len(t, log.Runs, 1)
+			assert.Len(t, log.Runs[0].Tool.Driver.Rules, 5)
+			assert.Equal(t, tt.want, log.Runs[0].Results)
+		})
+	}
+}
+
+func sarifLocations(uri string, line int) []sarifLocation {
+	return []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: uri, URIBaseID: "%SRCROOT%"},
+		Region:           &sarifRegion{StartLine: line},
+	}}}
+}
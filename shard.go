@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const timingsFile = "timings.json"
+
+var ErrInvalidShard = errors.New("invalid shard")
+
+// Shard is one of the parts the packages are split into, Index starts at 1.
+type Shard struct {
+	Index int
+	Total int
+}
+
+func ParseShard(s string) (Shard, error) {
+	index, total, ok := strings.Cut(s, "/")
+	if !ok {
+		return Shard{}, fmt.Errorf("%w %q, must be i/n, e.g. 1/4", ErrInvalidShard, s)
+	}
+
+	shard := Shard{}
+	var err1, err2 error
+	shard.Index, err1 = strconv.Atoi(index)
+	shard.Total, err2 = strconv.Atoi(total)
+
+	if err1 != nil || err2 != nil || shard.Total < 1 || shard.Index < 1 || shard.Index > shard.Total {
+		return Shard{}, fmt.Errorf("%w %q, must be i/n with 1 <= i <= n, e.g. 1/4", ErrInvalidShard, s)
+	}
+
+	return shard, nil
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Total)
+}
+
+// Packages splits the packages between the shards, balancing them by how long they took to run before,
+// and returns the ones that belong to this shard. Packages without timings count as the average duration
+// and, without any timings, every package counts the same.
+func (s Shard) Packages(pkgs []string, timings Timings) []string {
+	total := 0.0
+	known := 0
+	for _, pkg := range pkgs {
+		if elapsed, ok := timings.Packages[pkg]; ok {
+			total += elapsed
+			known++
+		}
+	}
+
+	average := 1.0
+	if known > 0 && total > 0 {
+		average = total / float64(known)
+	}
+
+	weight := func(pkg string) float64 {
+		if elapsed, ok := timings.Packages[pkg]; ok && total > 0 {
+			return elapsed
+		}
+		return average
+	}
+
+	sorted := slices.Clone(pkgs)
+	slices.SortFunc(sorted, func(a, b string) int {
+		if c := cmp.Compare(weight(b), weight(a)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+
+	// The slowest packages go first to the shard with less work, so every node computes the same split
+	loads := make([]float64, s.Total)
+	result := []string{}
+	for _, pkg := range sorted {
+		shard := 0
+		for i, load := range loads {
+			if load < loads[shard] {
+				shard = i
+			}
+		}
+
+		loads[shard] += weight(pkg)
+		if shard == s.Index-1 {
+			result = append(result, pkg)
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// Timings stores how long each package took to run, in seconds.
+type Timings struct {
+	Packages map[string]float64 `json:"packages"`
+}
+
+func LoadTimings(path string) (Timings, error) {
+	timings := Timings{Packages: make(map[string]float64)}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return timings, nil
+	}
+	if err != nil {
+		return timings, err
+	}
+
+	if err := json.Unmarshal(content, &timings); err != nil {
+		return timings, err
+	}
+
+	if timings.Packages == nil {
+		timings.Packages = make(map[string]float64)
+	}
+
+	return timings, nil
+}
+
+// SaveTimings updates the timings with the packages of the run. Cached packages keep their previous timing,
+// since they didn't really run.
+func SaveTimings(path string, timings Timings, run *Run) error {
+	for _, pkg := range run.Packages {
+		if pkg.Entry.PkgFinished && !pkg.Entry.Cached && !pkg.Entry.NoTestFiles && !pkg.Entry.BuildFailed {
+			timings.Packages[pkg.Name] = pkg.Entry.Elapsed
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(timings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// listTestPackages lists the packages that have tests, passing the flags that change which files are built.
+func listTestPackages(pkgs []string, flags []string) ([]string, error) {
+	args := []string{"list", "-e", "-f", "{{if or .TestGoFiles .XTestGoFiles}}{{.ImportPath}}{{end}}"}
+
+	for i := 0; i < len(flags); i++ {
+		name, _, hasValue := strings.Cut(strings.TrimLeft(flags[i], "-"), "=")
+		if name != "tags" && name != "mod" && name != "modfile" {
+			continue
+		}
+
+		args = append(args, flags[i])
+		if !hasValue && i+1 < len(flags) {
+			args = append(args, flags[i+1])
+			i++
+		}
+	}
+
+	if len(pkgs) == 0 {
+		pkgs = []string{"."}
+	}
+
+	cmd := exec.Command("go", append(args, pkgs...)...)
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if pkg := strings.TrimSpace(scanner.Text()); pkg != "" {
+			result = append(result, pkg)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseShard(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Shard
+		wantErr bool
+	}{
+		{"1/4", Shard{Index: 1, Total: 4}, false},
+		{"4/4", Shard{Index: 4, Total: 4}, false},
+		{"0/4", Shard{}, true},
+		{"5/4", Shard{}, true},
+		{"1", Shard{}, true},
+		{"a/b", Shard{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			shard, err := ParseShard(tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidShard)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, shard)
+		})
+	}
+}
+
+func TestShard_Packages(t *testing.T) {
+	pkgs := []string{"a", "b", "c", "d", "e"}
+
+	t.Run("balanced by timings", func(t *testing.T) {
+		timings := Timings{Packages: map[string]float64{"a": 10, "b": 6, "c": 3, "d": 2}}
+
+		// e has no timing and counts as the average, 5.25
+		assert.Equal(t, []string{"a"}, Shard{Index: 1, Total: 3}.Packages(pkgs, timings))
+		assert.Equal(t, []string{"b", "d"}, Shard{Index: 2, Total: 3}.Packages(pkgs, timings))
+		assert.Equal(t, []string{"c", "e"}, Shard{Index: 3, Total: 3}.Packages(pkgs, timings))
+	})
+
+	t.Run("balanced by count without timings", func(t *testing.T) {
+		timings := Timings{Packages: map[string]float64{}}
+
+		assert.Equal(t, []string{"a", "c", "e"}, Shard{Index: 1, Total: 2}.Packages(pkgs, timings))
+		assert.Equal(t, []string{"b", "d"}, Shard{Index: 2, Total: 2}.Packages(pkgs, timings))
+	})
+
+	t.Run("more shards than packages", func(t *testing.T) {
+		assert.Empty(t, Shard{Index: 3, Total: 3}.Packages([]string{"a", "b"}, Timings{}))
+	})
+}
+
+func TestSaveTimings(t *testing.T) {
+	a := assert.New(t)
+	path := filepath.Join(t.TempDir(), "timings.json")
+
+	processor := NewProcessor(Config{})
+	processTestdata(t, processor, "success_cached.txt")
+
+	timings := Timings{Packages: map[string]float64{
+		"github.com/joaopsramos/fincon/internal/api": 12.5,
+		"github.com/joaopsramos/fincon/internal/old": 1,
+	}}
+
+	a.NoError(SaveTimings(path, timings, processor.renderer.Run()))
+
+	saved, err := LoadTimings(path)
+	a.NoError(err)
+
+	// Cached packages keep the timing of when they really ran
+	a.Equal(12.5, saved.Packages["github.com/joaopsramos/fincon/internal/api"])
+	a.Equal(1.0, saved.Packages["github.com/joaopsramos/fincon/internal/old"])
+	a.NotContains(saved.Packages, "github.com/joaopsramos/fincon/cmd/fincon")
+}
+
+func TestSaveTimings_stdin(t *testing.T) {
+	dir := runFromStdin(t, Config{}, "fail.txt")
+
+	assert.NoFileExists(t, filepath.Join(dir, lastRunDir, timingsFile))
+}
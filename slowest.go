@@ -0,0 +1,114 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+type slowEntry struct {
+	name    string
+	elapsed time.Duration
+}
+
+// slowTests returns the tests and subtests of the run sorted from the slowest to the fastest.
+func slowTests(run *Run) []slowEntry {
+	entries := []slowEntry{}
+	for _, pkg := range run.Packages {
+		for _, t := range pkg.AllTests() {
+			if t.Action == "skip" {
+				continue
+			}
+
+			entries = append(entries, slowEntry{name: pkg.Name + " " + t.Name, elapsed: seconds(t.Elapsed)})
+		}
+	}
+
+	sortSlowest(entries)
+
+	return entries
+}
+
+// slowPackages returns the packages that ran, sorted from the slowest to the fastest.
+func slowPackages(run *Run) []slowEntry {
+	entries := []slowEntry{}
+	for _, pkg := range run.Packages {
+		if pkg.Entry.Cached || pkg.Entry.NoTestFiles || pkg.Entry.BuildFailed {
+			continue
+		}
+
+		entries = append(entries, slowEntry{name: pkg.Name, elapsed: seconds(pkg.Entry.Elapsed)})
+	}
+
+	sortSlowest(entries)
+
+	return entries
+}
+
+// printSlowest prints the n slowest tests and packages, or every test slower than the threshold when n is zero.
+// Tests over the threshold are highlighted.
+func (p *Processor) printSlowest() {
+	n := p.config.Slowest
+	threshold := p.config.SlowThreshold
+	run := p.renderer.Run()
+
+	tests := slowTests(run)
+	if n == 0 {
+		tests = slices.DeleteFunc(tests, func(e slowEntry) bool { return e.elapsed <= threshold })
+	}
+
+	if len(tests) == 0 {
+		return
+	}
+
+	if n > 0 && len(tests) > n {
+		tests = tests[:n]
+	}
+
+	p.renderer.diagnosticf("\n%s\n", blue.Sprint("Slowest tests:"))
+	p.printSlowEntries(tests, threshold)
+
+	if n > 0 {
+		pkgs := slowPackages(run)
+		if len(pkgs) > 0 {
+			p.renderer.diagnosticf("\n%s\n", blue.Sprint("Slowest packages:"))
+			p.printSlowEntries(pkgs[:min(len(pkgs), n)], 0)
+		}
+	}
+}
+
+func (p *Processor) printSlowEntries(entries []slowEntry, threshold time.Duration) {
+	for _, e := range entries {
+		line := fmt.Sprintf("\t%6.2fs\t%s", e.elapsed.Seconds(), e.name)
+		if threshold > 0 && e.elapsed > threshold {
+			line = color.RedString(line)
+		}
+
+		p.renderer.diagnosticf("%s\n", line)
+	}
+}
+
+// slowTestsOver counts the tests that took longer than the threshold.
+func slowTestsOver(run *Run, threshold time.Duration) int {
+	count := 0
+	for _, e := range slowTests(run) {
+		if e.elapsed > threshold {
+			count++
+		}
+	}
+
+	return count
+}
+
+func sortSlowest(entries []slowEntry) {
+	slices.SortStableFunc(entries, func(a, b slowEntry) int {
+		return cmp.Compare(b.elapsed, a.elapsed)
+	})
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_printSlowest(t *testing.T) {
+	processor := NewProcessor(Config{Slowest: 2})
+	output := processTestdata(t, processor, "success.txt")
+
+	assert.Contains(t, output, `103 tests
+
+Slowest tests:
+	  0.55s	github.com/joaopsramos/fincon/internal/api TestApi_CreateSessionRateLimiter
+	  0.25s	github.com/joaopsramos/fincon/internal/api TestApi_CreateUserRateLimiter
+
+Slowest packages:
+	  0.58s	github.com/joaopsramos/fincon/internal/api
+	  0.03s	github.com/joaopsramos/fincon/internal/repository
+`)
+}
+
+func Test_printSlowestThreshold(t *testing.T) {
+	color.NoColor = true
+
+	originalStdout := os.Stdout
+	t.Cleanup(func() {
+		os.Stdout = originalStdout
+	})
+
+	processor := NewProcessor(Config{SlowThreshold: 200 * time.Millisecond, SlowExitCode: 3})
+
+	file, err := os.Open(filepath.Join("testdata", "success.txt"))
+	assert.NoError(t, err)
+	defer file.Close()
+
+	code := 0
+	output := captureOutput(func() {
+		code = processor.Process(file)
+	})
+
+	assert.Equal(t, 3, code)
+	assert.Contains(t, output, `Slowest tests:
+	  0.55s	github.com/joaopsramos/fincon/internal/api TestApi_CreateSessionRateLimiter
+	  0.25s	github.com/joaopsramos/fincon/internal/api TestApi_CreateUserRateLimiter
+
+2 tests took longer than 200ms
+`)
+	assert.NotContains(t, output, "Slowest packages:")
+}
@@ -7,14 +7,17 @@ import (
 )
 
 type Summary struct {
-	Passed  int
-	Failed  int
-	Skipped int
-	Elapsed float64
+	Passed   int
+	Failed   int
+	Skipped  int
+	Flaky    int
+	Aborted  int
+	Elapsed  float64
+	Coverage *float64
 }
 
 func (s Summary) Total() int {
-	return s.Passed + s.Skipped + s.Failed
+	return s.Passed + s.Skipped + s.Failed + s.Flaky
 }
 
 func (s Summary) String() string {
@@ -36,5 +39,23 @@ func (s Summary) String() string {
 		output += yellow.Sprintf("%d skipped", s.Skipped)
 	}
 
+	if s.Flaky > 0 {
+		if s.Failed > 0 {
+			output += color.RedString(", ")
+		} else {
+			output += color.GreenString(", ")
+		}
+
+		output += yellow.Sprintf("%d flaky", s.Flaky)
+	}
+
+	if s.Coverage != nil {
+		output += fmt.Sprintf("\ncoverage: %.1f%% of statements", *s.Coverage)
+	}
+
+	if s.Aborted > 0 {
+		output += "\n" + yellow.Sprintf("%d packages aborted", s.Aborted)
+	}
+
 	return fmt.Sprintf("Finished in %.2fs\n%s", s.Elapsed, output)
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/joaopsramos/gotestpp/utils"
+)
+
+const tapIndent = "    "
+
+// TAPReporter prints the run using TAP version 14, see https://testanything.org/tap-version-14-specification.html.
+// Each package is a subtest and so is every test that has subtests.
+type TAPReporter struct{}
+
+func NewTAPReporter() *TAPReporter {
+	return &TAPReporter{}
+}
+
+func (t *TAPReporter) Report(run *Run) error {
+	var b strings.Builder
+
+	b.WriteString("TAP version 14\n")
+	fmt.Fprintf(&b, "1..%d\n", len(run.Packages))
+
+	for i, pkg := range run.Packages {
+		t.writePackage(&b, run, pkg, i+1)
+	}
+
+	fmt.Print(b.String())
+
+	return nil
+}
+
+func (t *TAPReporter) writePackage(b *strings.Builder, run *Run, pkg *PackageRun, number int) {
+	if len(pkg.Tests) > 0 {
+		fmt.Fprintf(b, "%s# Subtest: %s\n", tapIndent, pkg.Name)
+		fmt.Fprintf(b, "%s1..%d\n", tapIndent, len(pkg.Tests))
+
+		for i, test := range pkg.Tests {
+			t.writeTest(b, test, test, i+1, tapIndent)
+		}
+	}
+
+	switch {
+	case pkg.Entry.BuildFailed:
+		fmt.Fprintf(b, "not ok %d - %s\n", number, tapEscape(pkg.Name))
+		t.writeBuildFailure(b, run.BuildOutput(pkg.Name))
+
+	case pkg.Entry.NoTestFiles:
+		fmt.Fprintf(b, "ok %d - %s # SKIP no test files\n", number, tapEscape(pkg.Name))
+
+	case pkg.Status() == "fail":
+		fmt.Fprintf(b, "not ok %d - %s\n", number, tapEscape(pkg.Name))
+
+	default:
+		fmt.Fprintf(b, "ok %d - %s\n", number, tapEscape(pkg.Name))
+	}
+}
+
+func (t *TAPReporter) writeTest(b *strings.Builder, root TestEntry, test TestEntry, number int, indent string) {
+	subTests := root.DirectSubTests(test.Name)
+
+	if len(subTests) > 0 {
+		fmt.Fprintf(b, "%s%s# Subtest: %s\n", indent, tapIndent, test.Name)
+		fmt.Fprintf(b, "%s%s1..%d\n", indent, tapIndent, len(subTests))
+
+		for i, st := range subTests {
+			t.writeTest(b, root, st, i+1, indent+tapIndent)
+		}
+	}
+
+	switch test.Status() {
+	case "fail":
+		fmt.Fprintf(b, "%snot ok %d - %s\n", indent, number, tapEscape(test.Name))
+		t.writeFailure(b, indent, test)
+
+	case "skip":
+		fmt.Fprintf(b, "%sok %d - %s # SKIP %s\n", indent, number, tapEscape(test.Name), tapEscape(test.SkipReason()))
+
+	default:
+		fmt.Fprintf(b, "%sok %d - %s\n", indent, number, tapEscape(test.Name))
+	}
+}
+
+func (t *TAPReporter) writeFailure(b *strings.Builder, indent string, test TestEntry) {
+	yamlIndent := indent + "  "
+
+	fmt.Fprintf(b, "%s---\n", yamlIndent)
+	fmt.Fprintf(b, "%sduration_ms: %d\n", yamlIndent, int64(test.Elapsed*1000))
+
+	asserts := ParseTestifyAsserts(test.Output)
+
+	if len(asserts) == 0 {
+		if output := utils.StripANSI(formatOutput(test)); output != "" {
+			writeYAMLString(b, yamlIndent, yamlIndent, "output", output)
+		}
+	} else {
+		fmt.Fprintf(b, "%sasserts:\n", yamlIndent)
+	}
+
+	for _, assert := range asserts {
+		itemIndent := yamlIndent + "    "
+
+		writeYAMLString(b, yamlIndent+"  - ", itemIndent, "error", assert.ErrorText())
+		if messages := assert.MessageLines(); len(messages) > 0 {
+			writeYAMLString(b, itemIndent, itemIndent, "messages", strings.Join(messages, "\n"))
+		}
+
+		fmt.Fprintf(b, "%strace:\n", itemIndent)
+		for _, trace := range assert.TraceLines() {
+			fmt.Fprintf(b, "%s  - %s\n", itemIndent, strconv.Quote(trace))
+		}
+	}
+
+	fmt.Fprintf(b, "%s...\n", yamlIndent)
+}
+
+func (t *TAPReporter) writeBuildFailure(b *strings.Builder, output string) {
+	b.WriteString("  ---\n")
+	b.WriteString("  message: \"build failed\"\n")
+	writeYAMLString(b, "  ", "  ", "output", output)
+	b.WriteString("  ...\n")
+}
+
+// writeYAMLString writes a key with a string value, using a literal block for multiline values.
+// prefix is written before the key, indent is the indentation of the key itself.
+func writeYAMLString(b *strings.Builder, prefix string, indent string, key string, value string) {
+	value = strings.TrimRight(value, "\n")
+
+	// Block scalars can't start with a space, the indentation would be detected wrong
+	if !strings.Contains(value, "\n") || strings.HasPrefix(value, " ") {
+		fmt.Fprintf(b, "%s%s: %s\n", prefix, key, strconv.Quote(value))
+		return
+	}
+
+	fmt.Fprintf(b, "%s%s: |-\n", prefix, key)
+	for _, line := range strings.Split(value, "\n") {
+		fmt.Fprintf(b, "%s  %s\n", indent, line)
+	}
+}
+
+func tapEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "#", `\#`, "\n", " ").Replace(s)
+}
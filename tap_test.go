@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tapOutput(t *testing.T) {
+	a := assert.New(t)
+
+	output := processTestdata(t, NewProcessor(Config{Format: TAPFormat}), "fail_with_skip.txt")
+
+	a.True(strings.HasPrefix(output, "TAP version 14\n1..11\n"))
+	a.NotContains(output, "Finished in")
+	a.Contains(output, "ok 1 - github.com/joaopsramos/fincon/cmd/fincon # SKIP no test files\n")
+	a.Contains(output, `    # Subtest: github.com/joaopsramos/fincon/internal/service
+    1..3
+    ok 1 - TestExpenseService_Create # SKIP expense_test.go:205
+`)
+	a.Contains(output, `    not ok 3 - TestPostgresExpense_GetSummary
+      ---
+      duration_ms: 10
+      asserts:
+        - error: |-
+            Not equal:
+            expected: 1
+            actual  : 2
+          trace:
+            - "/home/joao/www/fincon/backend/internal/service/expense_test.go:201"
+      ...
+not ok 11 - github.com/joaopsramos/fincon/internal/service
+`)
+}
+
+func Test_tapOutputDiagnostics(t *testing.T) {
+	a := assert.New(t)
+
+	output := ""
+	diagnostics := captureStderr(func() {
+		output = processTestdata(t, NewProcessor(Config{Format: TAPFormat, CoverageMin: 30}), "coverage.txt")
+	})
+
+	a.True(strings.HasPrefix(output, "TAP version 14\n"))
+	a.NotContains(output, "Coverage below the minimum")
+	a.Contains(diagnostics, "Coverage below the minimum:\n\t 25.0% <  30.0%\texample.com/cov/a\n")
+
+	diagnostics = captureStderr(func() {
+		output = processTestdata(t, NewProcessor(Config{Format: TAPFormat}), "build_failed_deps.txt")
+	})
+
+	a.NotContains(output, "Errors:")
+	a.Contains(diagnostics, "Errors:\n")
+	a.Contains(diagnostics, "Build errors:\n")
+}
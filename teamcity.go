@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joaopsramos/gotestpp/utils"
+)
+
+// TeamCityReporter prints TeamCity service messages, see
+// https://www.jetbrains.com/help/teamcity/service-messages.html#Reporting+Tests
+type TeamCityReporter struct{}
+
+func NewTeamCityReporter() *TeamCityReporter {
+	return &TeamCityReporter{}
+}
+
+func (t *TeamCityReporter) Report(run *Run) error {
+	for _, pkg := range run.Packages {
+		if pkg.Entry.NoTestFiles {
+			continue
+		}
+
+		teamCityMessage("testSuiteStarted", "name", pkg.Name)
+
+		if pkg.Entry.BuildFailed {
+			name := "[build failed]"
+			teamCityMessage("testStarted", "name", name)
+			teamCityMessage("testFailed", "name", name, "message", "build failed", "details", run.BuildOutput(pkg.Name))
+			teamCityMessage("testFinished", "name", name, "duration", "0")
+		}
+
+		for _, test := range pkg.AllTests() {
+			t.reportTest(test)
+		}
+
+		teamCityMessage("testSuiteFinished", "name", pkg.Name)
+	}
+
+	return nil
+}
+
+func (t *TeamCityReporter) reportTest(test TestEntry) {
+	teamCityMessage("testStarted", "name", test.Name)
+
+	switch test.Status() {
+	case "fail":
+		message := "Test failed"
+		if locations := TestLocations(test); len(locations) > 0 {
+			message, _, _ = strings.Cut(locations[0].Message, "\n")
+		}
+
+		details := utils.StripANSI(formatOutput(test))
+		teamCityMessage("testFailed", "name", test.Name, "message", message, "details", details)
+
+	case "skip":
+		teamCityMessage("testIgnored", "name", test.Name, "message", test.SkipReason())
+	}
+
+	duration := fmt.Sprintf("%d", int64(test.Elapsed*1000))
+	teamCityMessage("testFinished", "name", test.Name, "duration", duration)
+}
+
+func teamCityMessage(name string, attrs ...string) {
+	output := "##teamcity[" + name
+
+	for i := 0; i+1 < len(attrs); i += 2 {
+		output += fmt.Sprintf(" %s='%s'", attrs[i], teamCityEscape(attrs[i+1]))
+	}
+
+	fmt.Println(output + "]")
+}
+
+func teamCityEscape(s string) string {
+	return strings.NewReplacer(
+		"|", "||",
+		"'", "|'",
+		"\n", "|n",
+		"\r", "|r",
+		"[", "|[",
+		"]", "|]",
+		"\u0085", "|x",
+		"\u2028", "|l",
+		"\u2029", "|p",
+	).Replace(s)
+}
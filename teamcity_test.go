@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_teamCityOutput(t *testing.T) {
+	a := assert.New(t)
+
+	output := processTestdata(t, NewProcessor(Config{Format: TeamCityFormat}), "fail_with_skip.txt")
+
+	a.NotContains(output, "Finished in")
+	a.NotContains(output, "github.com/joaopsramos/fincon/cmd/fincon")
+	a.Contains(output, "##teamcity[testIgnored name='TestExpenseService_Create' message='expense_test.go:205']\n")
+	a.Contains(output, "##teamcity[testFailed name='TestPostgresExpense_GetSummary' message='Not equal:' "+
+		"details='\texpense_test.go:201:|n\tError:|n\t\tNot equal:|n\t\texpected: 1|n\t\tactual  : 2|n\tError Trace:|n"+
+		"\t\t/home/joao/www/fincon/backend/internal/service/expense_test.go:201']\n"+
+		"##teamcity[testFinished name='TestPostgresExpense_GetSummary' duration='10']\n")
+}
+
+func Test_teamCityEscape(t *testing.T) {
+	assert.Equal(t, "|[a|]|n|'b|' ||", teamCityEscape("[a]\n'b' |"))
+}
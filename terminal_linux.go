@@ -0,0 +1,33 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// enableKeyPresses disables the line buffering and echo of the terminal, so a key press can be read
+// without waiting for Enter. The returned function restores the terminal.
+func enableKeyPresses() (func(), error) {
+	fd := int(os.Stdin.Fd())
+
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return func() {}, err
+	}
+
+	original := *termios
+	termios.Lflag &^= unix.ICANON | unix.ECHO
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, termios); err != nil {
+		return func() {}, err
+	}
+
+	return func() {
+		unix.IoctlSetTermios(fd, unix.TCSETS, &original)
+	}, nil
+}
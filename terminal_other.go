@@ -0,0 +1,8 @@
+//go:build !linux
+
+package main
+
+// enableKeyPresses is only supported on Linux, elsewhere keys must be followed by Enter.
+func enableKeyPresses() (func(), error) {
+	return func() {}, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"strings"
+	"time"
 )
 
 type TestEntry struct {
@@ -9,15 +10,25 @@ type TestEntry struct {
 	Name         string
 	Pkg          string
 	Elapsed      float64
+	Start        time.Time
+	End          time.Time
 	Action       string
 	Output       string
 	SubTests     []TestEntry
+	Benchmarks   []Benchmark
+	FuzzInput    string
+	Coverage     *float64
+	FailedBuild  string
+	FuzzProgress string
 	NoTestFiles  bool
 	PkgFinished  bool
 	PkgHasErrors bool
 	Cached       bool
 	BuildFailed  bool
 	Panicked     bool
+	Unfinished   bool
+	Paused       bool
+	Flaky        bool
 }
 
 func (t TestEntry) RootTestName() string {
@@ -43,3 +54,29 @@ func (t *TestEntry) FilterSubTestsByAction(action string) []TestEntry {
 
 	return result
 }
+
+// Status is the action of the test, except for flaky tests, which failed but passed when rerun.
+func (t TestEntry) Status() string {
+	if t.Flaky {
+		return "pass"
+	}
+
+	return t.Action
+}
+
+func (t TestEntry) SkipReason() string {
+	return strings.TrimSuffix(strings.TrimSpace(t.Output), ":")
+}
+
+// DirectSubTests returns the subtests that are immediate children of the given test.
+func (t TestEntry) DirectSubTests(parent string) []TestEntry {
+	result := []TestEntry{}
+	for _, tt := range t.SubTests {
+		name, ok := strings.CutPrefix(tt.Name, parent+"/")
+		if ok && !strings.Contains(name, "/") {
+			result = append(result, tt)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,40 @@
+package calc
+
+import "errors"
+
+var ErrDivByZero = errors.New("division by zero")
+
+type Calculator struct {
+	memory int
+}
+
+func Add(a, b int) int {
+	return a + b
+}
+
+func Div(a, b int) (int, error) {
+	if b == 0 {
+		return 0, ErrDivByZero
+	}
+
+	return a / b, nil
+}
+
+func Sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+
+	return 0
+}
+
+func (c *Calculator) Store(n int) {
+	c.memory = n
+}
+
+func (c Calculator) Recall() int {
+	return c.memory
+}
@@ -1,13 +1,29 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/joaopsramos/gotestpp/utils"
 )
 
+type LineKind int
+
+const (
+	PlainLine LineKind = iota
+	TitleLine
+	RemovedLine
+	AddedLine
+)
+
+type AssertLine struct {
+	Text string
+	Kind LineKind
+}
+
 type TestifyAssert struct {
 	Error   []string
 	Trace   []string
@@ -76,27 +92,70 @@ Loop:
 }
 
 func (t TestifyAssert) String() string {
-	output := t.formatError()
-	output += t.formatMessages()
-	output += "\n" + t.formatTrace()
+	return t.styled(terminalStyle)
+}
+
+func (t TestifyAssert) styled(style outputStyle) string {
+	output := t.formatError(style)
+	output += t.formatMessages(style)
+	output += "\n" + t.formatTrace(style)
 
 	return output
 }
 
-func (t TestifyAssert) formatError() string {
+func (t TestifyAssert) formatError(style outputStyle) string {
 	output := make([]string, 0, len(t.Error))
 
+	for _, line := range t.ErrorLines() {
+		switch line.Kind {
+		case TitleLine, RemovedLine:
+			output = append(output, style.color(color.FgRed, line.Text))
+		case AddedLine:
+			output = append(output, style.color(color.FgGreen, line.Text))
+		default:
+			output = append(output, style.text(line.Text))
+		}
+	}
+
+	return fmt.Sprintf("\t%s\n\t\t%s", "Error:", strings.Join(output, "\n\t\t"))
+}
+
+func (t TestifyAssert) formatMessages(style outputStyle) string {
+	if len(t.Message) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("\n\tMessages:\n\t\t%s", style.text(strings.Join(t.MessageLines(), "\n\t\t")))
+}
+
+func (t TestifyAssert) formatTrace(style outputStyle) string {
+	trace := slices.Clone(t.Trace)
+	trace[0] = strings.Replace(trace[0], "Error Trace:", "", 1)
+
+	return fmt.Sprintf("\t%s\n\t%s", "Error Trace:", style.text(strings.Join(trace, "\n\t\t")))
+}
+
+// ErrorLines returns the lines of the error without testify's indentation, marking the ones
+// that are part of a diff.
+func (t TestifyAssert) ErrorLines() []AssertLine {
+	output := make([]AssertLine, 0, len(t.Error))
+
+	// A trace logged by hand, without the rest of the assert
+	if len(t.Error) == 0 {
+		return output
+	}
+
 	// Replace to get the correct indentation count
 	firstLine := strings.Replace(t.Error[0], "Error:", "      ", 1)
 	baseIndent := utils.CountSpacesAndTabs(firstLine)
 
-	output = append(output, color.RedString(strings.TrimSpace(firstLine)))
+	output = append(output, AssertLine{Text: strings.TrimSpace(firstLine), Kind: TitleLine})
 
 	for _, line := range t.Error[1:] {
 		trimmed := strings.TrimSpace(line)
 
 		if trimmed == "" {
-			output = append(output, "")
+			output = append(output, AssertLine{})
 			continue
 		}
 
@@ -110,36 +169,65 @@ func (t TestifyAssert) formatError() string {
 			line = " " + line
 		}
 
+		kind := PlainLine
 		if sameIndent && strings.HasPrefix(trimmed, "-") {
-			line = color.RedString(line)
+			kind = RemovedLine
 		} else if sameIndent && strings.HasPrefix(trimmed, "+") {
-			line = color.GreenString(line)
+			kind = AddedLine
 		}
 
-		output = append(output, line)
+		output = append(output, AssertLine{Text: line, Kind: kind})
 	}
 
-	return fmt.Sprintf("\t%s\n\t\t%s", "Error:", strings.Join(output, "\n\t\t"))
+	return output
 }
 
-func (t TestifyAssert) formatMessages() string {
-	if len(t.Message) == 0 {
-		return ""
+func (t TestifyAssert) ErrorText() string {
+	errorLines := t.ErrorLines()
+	lines := make([]string, len(errorLines))
+	for i, line := range errorLines {
+		lines[i] = line.Text
 	}
 
+	return strings.Join(lines, "\n")
+}
+
+func (t TestifyAssert) MessageLines() []string {
 	output := make([]string, 0, len(t.Message))
 
-	t.Message[0] = strings.Replace(t.Message[0], "Messages:", "         ", 1)
-	for _, line := range t.Message {
-		line = utils.StripExtraSpacesAndTabs(line)
-		output = append(output, line)
+	for i, line := range t.Message {
+		if i == 0 {
+			line = strings.Replace(line, "Messages:", "         ", 1)
+		}
+
+		output = append(output, utils.StripExtraSpacesAndTabs(line))
 	}
 
-	return fmt.Sprintf("\n\tMessages:\n\t\t%s", strings.Join(output, "\n\t\t"))
+	return output
 }
 
-func (t TestifyAssert) formatTrace() string {
-	t.Trace[0] = strings.Replace(t.Trace[0], "Error Trace:", "", 1)
+func (t TestifyAssert) TraceLines() []string {
+	output := make([]string, len(t.Trace))
+	for i, line := range t.Trace {
+		output[i] = strings.TrimSpace(strings.TrimPrefix(line, "Error Trace:"))
+	}
+
+	return output
+}
+
+func (t TestifyAssert) TestName() string {
+	return strings.TrimSpace(strings.TrimPrefix(t.Test, "Test:"))
+}
+
+func ParseTestifyAsserts(output string) []TestifyAssert {
+	asserts := []TestifyAssert{}
+	scanner := NewRewindScanner(bufio.NewScanner(strings.NewReader(output)))
+
+	for scanner.Scan() {
+		if IsTestifyAssert(scanner.Text()) {
+			asserts = append(asserts, NewTestifyAssert(scanner.Text(), scanner))
+		}
+	}
 
-	return fmt.Sprintf("\t%s\n\t%s", "Error Trace:", strings.Join(t.Trace, "\n\t\t"))
+	return asserts
 }
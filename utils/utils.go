@@ -1,6 +1,9 @@
 package utils
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
 
 func StripExtraSpacesAndTabs(s string) string {
 	s = strings.Replace(s, " ", "", countSpaces(s))
@@ -40,3 +43,9 @@ Loop:
 
 	return count
 }
+
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func StripANSI(s string) string {
+	return ansiRe.ReplaceAllString(s, "")
+}
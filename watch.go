@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	watchPollInterval = 300 * time.Millisecond
+	clearScreen       = "\033[H\033[2J"
+)
+
+type Watcher struct {
+	config  Config
+	module  Module
+	flags   []string
+	pkgs    []string
+	files   map[string]time.Time
+	lastRun *Run
+}
+
+func NewWatcher(config Config) (*Watcher, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := FindModule(cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	flags, pkgs := splitGoTestArgs(config.GoTestArgs)
+
+	return &Watcher{config: config, module: module, flags: flags, pkgs: pkgs}, nil
+}
+
+func (w *Watcher) Run() int {
+	restore, err := enableKeyPresses()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, yellow.Sprintf("could not read key presses, keys must be followed by Enter: %s", err))
+	}
+	defer restore()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	w.files = w.scan()
+
+	changes := make(chan []string)
+	keys := make(chan byte)
+	go w.watchFiles(changes)
+	go readKeys(keys)
+
+	done := make(chan *Run, 1)
+	cancel := func() {}
+	running := false
+
+	start := func(args []string) {
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		cancel = cancelCtx
+		running = true
+
+		go func() {
+			done <- w.test(ctx, args)
+		}()
+	}
+
+	// Cancels the run in progress, if any, and waits for it to finish
+	stop := func() {
+		if running {
+			cancel()
+			<-done
+			running = false
+		}
+	}
+
+	start(w.args(w.pkgs))
+
+	for {
+		select {
+		case files := <-changes:
+			pkgs := w.affectedPackages(files)
+
+			// Changes to files no watched package depends on, e.g. of a tool, don't stop the run in progress
+			if len(pkgs) == 0 {
+				if !running {
+					fmt.Println(yellow.Sprint("No watched package depends on the changed files"))
+				}
+				continue
+			}
+
+			stop()
+			start(w.args(pkgs))
+
+		case key := <-keys:
+			switch key {
+			case 'a':
+				stop()
+				start(w.args(w.pkgs))
+
+			case 'f':
+				args, ok := w.failedArgs()
+				if !ok {
+					fmt.Println(color.GreenString("No failed tests to rerun"))
+					continue
+				}
+
+				stop()
+				start(args)
+
+			case 'q':
+				stop()
+				return 0
+			}
+
+		case run := <-done:
+			running = false
+			if run != nil {
+				w.lastRun = run
+			}
+
+			w.printHelp()
+
+		case <-signals:
+			stop()
+			return 0
+		}
+	}
+}
+
+// test runs go test with the given args, returning nil when the run was canceled.
+func (w *Watcher) test(ctx context.Context, args []string) *Run {
+	fmt.Print(clearScreen)
+	fmt.Println(blue.Sprintf("go test %s", strings.Join(args, " ")))
+
+	config := w.config
+	config.GoTestArgs = args
+
+	processor := NewProcessor(config)
+	processor.runWithCmd(ctx)
+
+	if ctx.Err() != nil {
+		fmt.Println(yellow.Sprint("\nCanceled"))
+		return nil
+	}
+
+	return processor.renderer.Run()
+}
+
+func (w *Watcher) args(pkgs []string) []string {
+	return append(slices.Clone(pkgs), w.flags...)
+}
+
+// failedArgs builds the args to run only the tests that failed in the last run.
+func (w *Watcher) failedArgs() ([]string, bool) {
+	if w.lastRun == nil {
+		return nil, false
+	}
+
+	pkgs := []string{}
+	names := []string{}
+
+	for _, pkg := range w.lastRun.Packages {
+		failed := pkg.FailedTests()
+		if len(failed) == 0 {
+			continue
+		}
+
+		pkgs = append(pkgs, pkg.Name)
+		for _, t := range failed {
+			names = append(names, regexp.QuoteMeta(t.Name))
+		}
+	}
+
+	if len(pkgs) == 0 {
+		return nil, false
+	}
+
+	return append(w.args(pkgs), "-run", fmt.Sprintf("^(%s)$", strings.Join(names, "|"))), true
+}
+
+// affectedPackages returns the packages, among the ones being watched, that contain or depend on the changed files.
+// All of them are returned when the packages can't be listed.
+func (w *Watcher) affectedPackages(files []string) []string {
+	changed := []string{}
+	for _, file := range files {
+		rel, err := filepath.Rel(w.module.Dir, filepath.Dir(file))
+		if err != nil {
+			continue
+		}
+
+		pkg := w.module.Path
+		if rel != "." {
+			pkg += "/" + filepath.ToSlash(rel)
+		}
+
+		if !slices.Contains(changed, pkg) {
+			changed = append(changed, pkg)
+		}
+	}
+
+	// With -test, go list also reports the test binaries, whose deps include the ones of the test files
+	args := []string{"list", "-e", "-test", "-f", "{{.ImportPath}}\t{{join .Deps \"\\t\"}}"}
+	output, err := exec.Command("go", append(args, w.pkgs...)...).Output()
+	if err != nil {
+		return w.pkgs
+	}
+
+	return affectedByChanges(output, changed)
+}
+
+// affectedByChanges reads the tab separated packages and deps listed by go list -test, returning the packages
+// that are or depend on one of the changed packages. Test variants, like "pkg [pkg.test]", "pkg_test [pkg.test]"
+// and "pkg.test", are reported as the package they test.
+func affectedByChanges(output []byte, changed []string) []string {
+	affected := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if fields[0] == "" {
+			continue
+		}
+
+		pkg := testedPackage(fields[0])
+		if slices.Contains(affected, pkg) {
+			continue
+		}
+
+		for _, field := range fields {
+			if slices.Contains(changed, testedPackage(field)) {
+				affected = append(affected, pkg)
+				break
+			}
+		}
+	}
+
+	return affected
+}
+
+func testedPackage(importPath string) string {
+	pkg, variant, _ := strings.Cut(importPath, " [")
+	if variant == "" {
+		pkg = strings.TrimSuffix(pkg, ".test")
+	}
+
+	return strings.TrimSuffix(pkg, "_test")
+}
+
+// watchFiles polls the module files, sending the changed ones once no more changes happen for one interval.
+func (w *Watcher) watchFiles(changes chan<- []string) {
+	pending := []string{}
+
+	for range time.Tick(watchPollInterval) {
+		files := w.scan()
+		changed := changedFiles(w.files, files)
+		w.files = files
+
+		for _, file := range changed {
+			if !slices.Contains(pending, file) {
+				pending = append(pending, file)
+			}
+		}
+
+		if len(changed) == 0 && len(pending) > 0 {
+			changes <- pending
+			pending = []string{}
+		}
+	}
+}
+
+func (w *Watcher) scan() map[string]time.Time {
+	files := make(map[string]time.Time)
+
+	filepath.WalkDir(w.module.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if path == w.module.Dir {
+				return nil
+			}
+
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" {
+				return filepath.SkipDir
+			}
+
+			// Nested modules are not part of this module
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasSuffix(path, ".go") {
+			if info, err := d.Info(); err == nil {
+				files[path] = info.ModTime()
+			}
+		}
+
+		return nil
+	})
+
+	return files
+}
+
+func (w *Watcher) printHelp() {
+	fmt.Printf("\n%s\n", blue.Sprint("Watching for changes. Press a to rerun all tests, f to rerun failures or q to quit."))
+}
+
+func changedFiles(before map[string]time.Time, after map[string]time.Time) []string {
+	changed := []string{}
+
+	for file, modTime := range after {
+		if previous, ok := before[file]; !ok || !previous.Equal(modTime) {
+			changed = append(changed, file)
+		}
+	}
+
+	for file := range before {
+		if _, ok := after[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+
+	slices.Sort(changed)
+
+	return changed
+}
+
+func readKeys(keys chan<- byte) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		key, err := reader.ReadByte()
+		if err != nil {
+			return
+		}
+
+		keys <- key
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_splitGoTestArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantFlags []string
+		wantPkgs  []string
+	}{
+		{"packages only", []string{"./...", "./cmd"}, nil, []string{"./...", "./cmd"}},
+		{"bool flags", []string{"-v", "./...", "-race"}, []string{"-v", "-race"}, []string{"./..."}},
+		{"flags with value", []string{"-run", "TestA", "./pkg", "-count=1"}, []string{"-run", "TestA", "-count=1"}, []string{"./pkg"}},
+		{"args", []string{"./pkg", "-args", "-v", "pkg"}, []string{"-args", "-v", "pkg"}, []string{"./pkg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, pkgs := splitGoTestArgs(tt.args)
+			assert.Equal(t, tt.wantFlags, flags)
+			assert.Equal(t, tt.wantPkgs, pkgs)
+		})
+	}
+}
+
+func Test_changedFiles(t *testing.T) {
+	now := time.Now()
+
+	before := map[string]time.Time{
+		"a.go": now,
+		"b.go": now,
+		"c.go": now,
+	}
+
+	after := map[string]time.Time{
+		"a.go": now,
+		"b.go": now.Add(time.Second),
+		"d.go": now,
+	}
+
+	assert.Equal(t, []string{"b.go", "c.go", "d.go"}, changedFiles(before, after))
+	assert.Empty(t, changedFiles(before, before))
+}
+
+func TestWatcher_failedArgs(t *testing.T) {
+	w := &Watcher{flags: []string{"-race"}, pkgs: []string{"./..."}}
+
+	_, ok := w.failedArgs()
+	assert.False(t, ok)
+
+	w.lastRun = &Run{Packages: []*PackageRun{
+		{Name: "example.com/a", Tests: []TestEntry{{Name: "TestA", Action: "fail"}, {Name: "TestB", Action: "pass"}}},
+		{Name: "example.com/b", Tests: []TestEntry{{Name: "TestC", Action: "pass"}}},
+		{Name: "example.com/c", Tests: []TestEntry{{Name: "TestD.x", Action: "fail"}}},
+	}}
+
+	args, ok := w.failedArgs()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"example.com/a", "example.com/c", "-race", "-run", `^(TestA|TestD\.x)$`}, args)
+}
+
+func Test_affectedByChanges(t *testing.T) {
+	output := []byte(strings.Join([]string{
+		"example.com/a\texample.com/lib",
+		"example.com/b\t",
+		"example.com/deep\t",
+		"example.com/helper\texample.com/deep",
+		"example.com/lib\t",
+		"example.com/a.test\tfmt\texample.com/a [example.com/a.test]\texample.com/lib\ttesting",
+		"example.com/a [example.com/a.test]\tfmt\texample.com/lib\ttesting",
+		"example.com/b.test\tfmt\texample.com/b [example.com/b.test]\texample.com/b_test [example.com/b.test]\texample.com/deep\texample.com/helper\ttesting",
+		"example.com/b [example.com/b.test]\tfmt\ttesting",
+		"example.com/b_test [example.com/b.test]\tfmt\texample.com/b [example.com/b.test]\texample.com/deep\texample.com/helper\ttesting",
+		"",
+	}, "\n"))
+
+	tests := []struct {
+		name    string
+		changed []string
+		want    []string
+	}{
+		{"package itself", []string{"example.com/b"}, []string{"example.com/b"}},
+		{"direct dependency", []string{"example.com/lib"}, []string{"example.com/a", "example.com/lib"}},
+		{"dependency of a test import", []string{"example.com/deep"}, []string{"example.com/deep", "example.com/helper", "example.com/b"}},
+		{"not watched", []string{"example.com/other"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, affectedByChanges(output, tt.changed))
+		})
+	}
+}